@@ -0,0 +1,701 @@
+package nodeostest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Akagi201/eosgo/ecc"
+	"github.com/Akagi201/eosgo/types"
+)
+
+type handler func(r *http.Request) (interface{}, *types.APIError)
+
+func (n *Node) chainHandlers() map[string]handler {
+	return map[string]handler{
+		"get_info":                    n.getInfo,
+		"get_block":                   n.getBlock,
+		"get_account":                 n.getAccount,
+		"get_code":                    n.getCode,
+		"get_abi":                     n.getABI,
+		"get_raw_abi":                 n.getRawABI,
+		"get_table_rows":              n.getTableRows,
+		"get_table_by_scope":          n.getTableByScope,
+		"get_currency_balance":        n.getCurrencyBalance,
+		"get_currency_stats":          n.getCurrencyStats,
+		"get_accounts_by_authorizers": n.getAccountsByAuthorizers,
+		"get_required_keys":           n.getRequiredKeys,
+		"compute_transaction":         n.computeTransaction,
+		"push_transaction":            n.pushTransaction,
+	}
+}
+
+func (n *Node) historyHandlers() map[string]handler {
+	return map[string]handler{
+		"get_transaction":         n.getTransaction,
+		"get_actions":             n.getActions,
+		"get_key_accounts":        n.getKeyAccounts,
+		"get_controlled_accounts": n.getControlledAccounts,
+	}
+}
+
+func decodeBody(r *http.Request, v interface{}) *types.APIError {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequest(err)
+	}
+	return nil
+}
+
+func (n *Node) getInfo(r *http.Request) (interface{}, *types.APIError) {
+	head, lib := n.head(), n.lib()
+	return &types.InfoResp{
+		ServerVersion:            "nodeostest",
+		ChainID:                  n.ChainID,
+		HeadBlockNum:             head.BlockNum,
+		HeadBlockID:              head.ID,
+		HeadBlockTime:            types.JSONTime{Time: head.Timestamp.Time},
+		HeadBlockProducer:        head.Producer,
+		LastIrreversibleBlockNum: lib.BlockNum,
+		LastIrreversibleBlockID:  lib.ID,
+	}, nil
+}
+
+func (n *Node) getBlock(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		BlockNumOrID string `json:"block_num_or_id"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	if id, err := hex.DecodeString(params.BlockNumOrID); err == nil && len(id) == 32 {
+		for _, block := range n.blocks {
+			if bytes.Equal(block.ID, id) {
+				return block, nil
+			}
+		}
+	} else if num, err := strconv.ParseUint(params.BlockNumOrID, 10, 32); err == nil && num > 0 && num <= uint64(len(n.blocks)) {
+		return n.blocks[num-1], nil
+	}
+
+	return nil, newError(3100002, string(types.ErrUnknownBlock), "Unknown block",
+		fmt.Sprintf("Could not find block: %s", params.BlockNumOrID))
+}
+
+func (n *Node) getAccount(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		AccountName types.AccountName `json:"account_name"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	account := n.accounts[params.AccountName]
+	if account == nil {
+		return nil, newError(3060002, "account_query_exception", "Account Query Exception",
+			fmt.Sprintf("unknown key (eosio::chain::name): %s", params.AccountName))
+	}
+	return account, nil
+}
+
+// getCode serves the ABI of the account, there is no contract code.
+func (n *Node) getCode(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		AccountName types.AccountName `json:"account_name"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	if n.accounts[params.AccountName] == nil {
+		return nil, newError(3060002, "account_query_exception", "Account Query Exception",
+			fmt.Sprintf("unknown key (eosio::chain::name): %s", params.AccountName))
+	}
+
+	resp := &types.GetCodeResp{
+		AccountName: params.AccountName,
+		CodeHash:    hex.EncodeToString(make([]byte, 32)),
+	}
+	if abi := n.abis[params.AccountName]; abi != nil {
+		resp.ABI = *abi
+	}
+	return resp, nil
+}
+
+func (n *Node) getABI(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		AccountName types.AccountName `json:"account_name"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &types.GetABIResp{AccountName: params.AccountName}
+	if abi := n.abis[params.AccountName]; abi != nil {
+		resp.ABI = *abi
+	}
+	return resp, nil
+}
+
+func (n *Node) getRawABI(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		AccountName types.AccountName `json:"account_name"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &types.GetRawABIResp{
+		AccountName: params.AccountName,
+		CodeHash:    make(types.SHA256Bytes, 32),
+		ABIHash:     make(types.SHA256Bytes, 32),
+	}
+	if abi := n.abis[params.AccountName]; abi != nil {
+		packed, err := abi.MarshalBinary()
+		if err != nil {
+			return nil, badRequest(err)
+		}
+		hash := sha256.Sum256(packed)
+		resp.ABI, resp.ABIHash = packed, hash[:]
+	}
+	return resp, nil
+}
+
+// getTableRows serves the primary index only, bounds being numbers or
+// names.
+func (n *Node) getTableRows(r *http.Request) (interface{}, *types.APIError) {
+	var params types.GetTableRowsRequest
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+	if params.IndexPosition != "" && params.IndexPosition != "1" && params.IndexPosition != "primary" {
+		return nil, badRequest(fmt.Errorf("index %q: only the primary index is supported", params.IndexPosition))
+	}
+
+	lower, err := parseBound(params.LowerBound, 0)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	upper, err := parseBound(params.UpperBound, ^uint64(0))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	limit := int(params.Limit)
+	if limit == 0 {
+		limit = 10
+	}
+
+	var matching []tableRow
+	for _, row := range n.tables[tableKey{params.Code, params.Scope, params.Table}] {
+		if row.primaryKey >= lower && row.primaryKey <= upper {
+			matching = append(matching, row)
+		}
+	}
+	if params.Reverse {
+		for i, j := 0, len(matching)-1; i < j; i, j = i+1, j-1 {
+			matching[i], matching[j] = matching[j], matching[i]
+		}
+	}
+
+	resp := struct {
+		Rows    []interface{} `json:"rows"`
+		More    bool          `json:"more"`
+		NextKey string        `json:"next_key"`
+	}{Rows: []interface{}{}}
+	if len(matching) > limit {
+		resp.More = true
+		resp.NextKey = strconv.FormatUint(matching[limit].primaryKey, 10)
+		matching = matching[:limit]
+	}
+
+	for _, row := range matching {
+		if params.JSON {
+			resp.Rows = append(resp.Rows, row.row)
+			continue
+		}
+
+		cnt, err := types.MarshalBinary(row.row)
+		if err != nil {
+			return nil, badRequest(fmt.Errorf("encoding row %d: %w", row.primaryKey, err))
+		}
+		resp.Rows = append(resp.Rows, hex.EncodeToString(cnt))
+	}
+	return resp, nil
+}
+
+// getTableByScope lists the scopes of the seeded tables, ordered like
+// `nodeos` by scope then table.  The payer is the contract.
+func (n *Node) getTableByScope(r *http.Request) (interface{}, *types.APIError) {
+	var params types.GetTableByScopeRequest
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	lower, err := parseBound(params.LowerBound, 0)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	upper, err := parseBound(params.UpperBound, ^uint64(0))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	limit := int(params.Limit)
+	if limit == 0 {
+		limit = 10
+	}
+
+	var matching []tableKey
+	for key, rows := range n.tables {
+		if key.code != params.Code || (params.Table != "" && key.table != params.Table) || len(rows) == 0 {
+			continue
+		}
+		if scope := scopeValue(key.scope); scope >= lower && scope <= upper {
+			matching = append(matching, key)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if left, right := scopeValue(matching[i].scope), scopeValue(matching[j].scope); left != right {
+			return left < right
+		}
+		return matching[i].table < matching[j].table
+	})
+	if params.Reverse {
+		for i, j := 0, len(matching)-1; i < j; i, j = i+1, j-1 {
+			matching[i], matching[j] = matching[j], matching[i]
+		}
+	}
+
+	resp := &types.GetTableByScopeResp{Rows: []types.TableScope{}}
+	if len(matching) > limit {
+		resp.More = matching[limit].scope
+		matching = matching[:limit]
+	}
+	for _, key := range matching {
+		resp.Rows = append(resp.Rows, types.TableScope{
+			Code:  types.AccountName(key.code),
+			Scope: types.ScopeName(key.scope),
+			Table: types.TableName(key.table),
+			Payer: types.AccountName(key.code),
+			Count: uint32(len(n.tables[key])),
+		})
+	}
+	return resp, nil
+}
+
+// scopeValue orders scopes like `nodeos`, by their value as names.
+func scopeValue(scope string) uint64 {
+	value, _ := types.StringToName(scope)
+	return value
+}
+
+// getCurrencyBalance reads the `accounts` table of the token contract,
+// scoped by account, like `eosio.token`.
+func (n *Node) getCurrencyBalance(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		Code    string `json:"code"`
+		Account string `json:"account"`
+		Symbol  string `json:"symbol"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	out := []types.Asset{}
+	for _, row := range n.tables[tableKey{params.Code, params.Account, "accounts"}] {
+		var account struct {
+			Balance types.Asset `json:"balance"`
+		}
+		if err := remarshal(row.row, &account); err != nil {
+			return nil, badRequest(err)
+		}
+		if params.Symbol == "" || account.Balance.Symbol.Symbol == params.Symbol {
+			out = append(out, account.Balance)
+		}
+	}
+	return out, nil
+}
+
+// getCurrencyStats reads the `stat` table of the token contract,
+// scoped by symbol (like "EOS") here.
+func (n *Node) getCurrencyStats(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		Code   string `json:"code"`
+		Symbol string `json:"symbol"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	out := map[string]types.CurrencyStats{}
+	for _, row := range n.tables[tableKey{params.Code, params.Symbol, "stat"}] {
+		var stats types.CurrencyStats
+		if err := remarshal(row.row, &stats); err != nil {
+			return nil, badRequest(err)
+		}
+		out[stats.Supply.Symbol.Symbol] = stats
+	}
+	return out, nil
+}
+
+// remarshal converts a seeded row to `v` through JSON.
+func remarshal(row interface{}, v interface{}) error {
+	cnt, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(cnt, v)
+}
+
+func parseBound(bound string, defaultValue uint64) (uint64, error) {
+	if bound == "" {
+		return defaultValue, nil
+	}
+	if value, err := strconv.ParseUint(bound, 10, 64); err == nil {
+		return value, nil
+	}
+	return types.StringToName(bound)
+}
+
+// getAccountsByAuthorizers lists the permissions having one of the
+// keys or accounts in their authority, accounts given by name matching
+// any of their permissions.
+func (n *Node) getAccountsByAuthorizers(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		Accounts []json.RawMessage `json:"accounts"`
+		Keys     []ecc.PublicKey   `json:"keys"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	var authorizers []types.PermissionLevel
+	for _, raw := range params.Accounts {
+		var level types.PermissionLevel
+		if err := json.Unmarshal(raw, &level.Actor); err != nil {
+			if err := json.Unmarshal(raw, &level); err != nil {
+				return nil, badRequest(err)
+			}
+		}
+		authorizers = append(authorizers, level)
+	}
+
+	resp := &types.AccountsByAuthorizersResp{Accounts: []types.AccountAuthorizer{}}
+	for _, account := range n.sortedAccounts() {
+		for _, perm := range account.Permissions {
+			authority := perm.RequiredAuth
+			for _, accountWeight := range authority.Accounts {
+				for _, authorizer := range authorizers {
+					level := accountWeight.Permission
+					if level.Actor == authorizer.Actor && (authorizer.Permission == "" || level.Permission == authorizer.Permission) {
+						resp.Accounts = append(resp.Accounts, types.AccountAuthorizer{
+							AccountName:        account.AccountName,
+							PermissionName:     types.PermissionName(perm.PermName),
+							AuthorizingAccount: &level,
+							Weight:             accountWeight.Weight,
+							Threshold:          authority.Threshold,
+						})
+						break
+					}
+				}
+			}
+			for _, keyWeight := range authority.Keys {
+				for _, key := range params.Keys {
+					if key.String() == keyWeight.PublicKey.String() {
+						key := key
+						resp.Accounts = append(resp.Accounts, types.AccountAuthorizer{
+							AccountName:    account.AccountName,
+							PermissionName: types.PermissionName(perm.PermName),
+							AuthorizingKey: &key,
+							Weight:         keyWeight.Weight,
+							Threshold:      authority.Threshold,
+						})
+						break
+					}
+				}
+			}
+		}
+	}
+	return resp, nil
+}
+
+func (n *Node) getRequiredKeys(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		Transaction   *types.Transaction `json:"transaction"`
+		AvailableKeys []ecc.PublicKey    `json:"available_keys"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+	if params.Transaction == nil {
+		return nil, badRequest(errors.New("missing transaction"))
+	}
+
+	keys, apiErr := n.requiredKeys(params.Transaction, params.AvailableKeys)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return &types.GetRequiredKeysResp{RequiredKeys: keys}, nil
+}
+
+// requiredKeys returns the keys of `available` needed to satisfy all
+// the authorizations of `tx`.
+func (n *Node) requiredKeys(tx *types.Transaction, available []ecc.PublicKey) ([]ecc.PublicKey, *types.APIError) {
+	var required []ecc.PublicKey
+	seen := map[string]bool{}
+
+	actions := append(append([]*types.Action{}, tx.ContextFreeActions...), tx.Actions...)
+	for _, action := range actions {
+		for _, level := range action.Authorization {
+			keys, ok := n.satisfy(level, available, 0)
+			if !ok {
+				return nil, newError(3090003, string(types.ErrUnsatisfiedAuthorization), "Unsatisfied authorization",
+					fmt.Sprintf("transaction declares authority '{\"actor\":\"%s\",\"permission\":\"%s\"}', but does not have signatures for it.", level.Actor, level.Permission))
+			}
+
+			for _, key := range keys {
+				if !seen[key.String()] {
+					seen[key.String()] = true
+					required = append(required, key)
+				}
+			}
+		}
+	}
+	return required, nil
+}
+
+// satisfy returns keys of `available` reaching the threshold of the
+// `level` permission, following the accounts in its authority.
+func (n *Node) satisfy(level types.PermissionLevel, available []ecc.PublicKey, depth int) ([]ecc.PublicKey, bool) {
+	if depth > 4 {
+		return nil, false
+	}
+
+	account := n.accounts[level.Actor]
+	if account == nil {
+		return nil, false
+	}
+
+	for _, perm := range account.Permissions {
+		if perm.PermName != string(level.Permission) {
+			continue
+		}
+
+		authority := perm.RequiredAuth
+		var keys []ecc.PublicKey
+		var weight uint32
+		for _, keyWeight := range authority.Keys {
+			if weight >= authority.Threshold {
+				break
+			}
+			for _, key := range available {
+				if key.String() == keyWeight.PublicKey.String() {
+					keys = append(keys, key)
+					weight += uint32(keyWeight.Weight)
+					break
+				}
+			}
+		}
+		for _, accountWeight := range authority.Accounts {
+			if weight >= authority.Threshold {
+				break
+			}
+			if subKeys, ok := n.satisfy(accountWeight.Permission, available, depth+1); ok {
+				keys = append(keys, subKeys...)
+				weight += uint32(accountWeight.Weight)
+			}
+		}
+		return keys, weight >= authority.Threshold
+	}
+	return nil, false
+}
+
+func (n *Node) pushTransaction(r *http.Request) (interface{}, *types.APIError) {
+	var packed types.PackedTransaction
+	if apiErr := decodeBody(r, &packed); apiErr != nil {
+		return nil, apiErr
+	}
+
+	signedTx, id, apiErr := n.checkTransaction(&packed)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	signers, err := signedTx.SignedByKeys(n.ChainID)
+	if err != nil {
+		return nil, badRequest(fmt.Errorf("recovering signatures: %w", err))
+	}
+	if _, apiErr := n.requiredKeys(signedTx.Transaction, signers); apiErr != nil {
+		return nil, apiErr
+	}
+
+	n.txs[hex.EncodeToString(id)] = &txRecord{id: id, signedTx: signedTx}
+	n.pushed = append(n.pushed, &packed)
+	n.pending = append(n.pending, types.TransactionReceipt{
+		TransactionReceiptHeader: types.TransactionReceiptHeader{
+			Status:               types.TransactionStatusExecuted,
+			CPUUsageMicroSeconds: 100,
+			NetUsageWords:        types.Varuint32(netUsageWords(&packed)),
+		},
+		Transaction: types.TransactionWithID{ID: id, Packed: &packed},
+	})
+	if !n.ManualBlocks {
+		n.produceBlock()
+	}
+
+	return &types.PushTransactionFullResp{
+		TransactionID: hex.EncodeToString(id),
+		Processed: types.TransactionProcessed{
+			Status:       "executed",
+			ID:           id,
+			ActionTraces: []types.ActionTrace{},
+		},
+	}, nil
+}
+
+// computeTransaction checks the transaction like pushTransaction,
+// without the signatures, and bills it like pushTransaction would.
+// Nothing executes, so the traces only have the actions.
+func (n *Node) computeTransaction(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		Transaction *types.PackedTransaction `json:"transaction"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+	if params.Transaction == nil {
+		return nil, badRequest(errors.New("missing transaction"))
+	}
+
+	signedTx, id, apiErr := n.checkTransaction(params.Transaction)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &types.ComputeTransactionResp{TransactionID: id}
+	resp.Processed.Receipt = &types.TransactionReceiptHeader{
+		Status:               types.TransactionStatusExecuted,
+		CPUUsageMicroSeconds: 100,
+		NetUsageWords:        types.Varuint32(netUsageWords(params.Transaction)),
+	}
+	resp.Processed.Elapsed = 100
+	resp.Processed.NetUsage = uint64(resp.Processed.Receipt.NetUsageWords) * 8
+	resp.Processed.ActionTraces = []types.ComputedActionTrace{}
+	for _, action := range signedTx.Actions {
+		resp.Processed.ActionTraces = append(resp.Processed.ActionTraces, types.ComputedActionTrace{
+			Receiver:         action.Account,
+			Action:           action,
+			AccountRAMDeltas: []types.AccountRAMDelta{},
+		})
+	}
+	return resp, nil
+}
+
+// netUsageWords bills the packed transaction about like `nodeos`: its
+// size with the signatures (66 bytes each) and the context-free data,
+// plus 12 bytes of overhead, in 8 bytes words.
+func netUsageWords(packed *types.PackedTransaction) uint32 {
+	size := len(packed.PackedTransaction) + len(packed.PackedContextFreeData) + 66*len(packed.Signatures) + 12
+	return uint32(size+7) / 8
+}
+
+// checkTransaction unpacks `packed` and checks it could go in the next
+// block: not a duplicate, not expired, with a valid TaPoS, and within
+// its NET limit.
+func (n *Node) checkTransaction(packed *types.PackedTransaction) (*types.SignedTransaction, types.SHA256Bytes, *types.APIError) {
+	signedTx, err := packed.Unpack()
+	if err != nil {
+		return nil, nil, newError(3040001, "tx_decompression_error", "Error decompressing transaction", err.Error())
+	}
+	id, err := packed.TransactionID()
+	if err != nil {
+		return nil, nil, badRequest(err)
+	}
+
+	if n.txs[hex.EncodeToString(id)] != nil {
+		return nil, nil, newError(3040008, string(types.ErrTxDuplicate), "Duplicate transaction",
+			fmt.Sprintf("duplicate transaction %x", []byte(id)))
+	}
+
+	now := time.Now().UTC()
+	if head := n.head().Timestamp.Time; head.After(now) {
+		now = head
+	}
+	expiration := signedTx.Expiration.Time
+	if !expiration.After(now) {
+		return nil, nil, newError(3040005, string(types.ErrExpiredTx), "Expired Transaction",
+			fmt.Sprintf("transaction has expired, expiration is %s and pending block time is %s", expiration.Format(time.RFC3339), now.Format(time.RFC3339)))
+	}
+	if expiration.After(now.Add(time.Hour)) {
+		return nil, nil, newError(3040006, string(types.ErrTxExpirationTooFar), "Transaction Expiration Too Far",
+			fmt.Sprintf("Transaction expiration is too far in the future relative to the reference time of %s, expiration is %s and the maximum transaction lifetime is 3600 seconds", now.Format(time.RFC3339), expiration.Format(time.RFC3339)))
+	}
+
+	if !n.validRefBlock(signedTx.RefBlockNum, signedTx.RefBlockPrefix) {
+		return nil, nil, newError(3040007, string(types.ErrInvalidRefBlock), "Invalid Reference Block",
+			"Transaction's reference block did not match. Is this transaction from a different fork?")
+	}
+
+	if limit, billed := uint32(signedTx.MaxNetUsageWords), netUsageWords(packed); limit != 0 && billed > limit {
+		return nil, nil, newError(3080002, string(types.ErrTxNetUsageExceeded), "Transaction exceeded the current network usage limit imposed on the transaction",
+			fmt.Sprintf("transaction net usage is too high: %d > %d", billed*8, limit*8))
+	}
+	return signedTx, id, nil
+}
+
+// validRefBlock checks the TaPoS fields against the most recent block
+// matching the lower 16 bits of the reference block number.
+func (n *Node) validRefBlock(refBlockNum uint16, refBlockPrefix uint32) bool {
+	head := n.head().BlockNum
+	num := head&^0xffff | uint32(refBlockNum)
+	if num > head {
+		if num < 0x10000 {
+			return false
+		}
+		num -= 0x10000
+	}
+	if num == 0 {
+		return false
+	}
+	return n.blocks[num-1].RefBlockPrefix == refBlockPrefix
+}
+
+func (n *Node) getTransaction(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		ID string `json:"id"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	record := n.txs[params.ID]
+	if record == nil || record.blockNum == 0 {
+		return nil, newError(3040011, string(types.ErrTxNotFound), "The transaction can not be found",
+			fmt.Sprintf("Transaction %s not found in history and no block hint was given", params.ID))
+	}
+
+	block := n.blocks[record.blockNum-1]
+	resp := &types.TransactionResp{
+		ID:                    record.id,
+		BlockTime:             types.JSONTime{Time: block.Timestamp.Time},
+		BlockNum:              block.BlockNum,
+		LastIrreversibleBlock: n.lib().BlockNum,
+		Traces:                []types.TransactionTrace{},
+	}
+	resp.Transaction.Transaction = *record.signedTx
+	for _, receipt := range block.Transactions {
+		if bytes.Equal(receipt.Transaction.ID, record.id) {
+			resp.Receipt.Status = receipt.Status
+			resp.Receipt.CPUUsageMicrosec = int(receipt.CPUUsageMicroSeconds)
+			resp.Receipt.NetUsageWords = int(receipt.NetUsageWords)
+			resp.Receipt.PackedTransaction = receipt.Transaction
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,367 @@
+package nodeostest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Akagi201/eosgo/types"
+)
+
+// Interaction is a recorded HTTP call.
+type Interaction struct {
+	Endpoint   string          `json:"endpoint"` // "chain/get_info"
+	Request    json.RawMessage `json:"request,omitempty"`
+	StatusCode int             `json:"status_code"`
+
+	// Response is the body received when it's JSON, ResponseText
+	// otherwise (like the HTML page of a proxy).
+	Response     json.RawMessage `json:"response,omitempty"`
+	ResponseText string          `json:"response_text,omitempty"`
+}
+
+// Fixture is the content of a fixture file.
+type Fixture struct {
+	// Note tells where the interactions come from, like the node they
+	// were recorded from or the program that generated them.
+	Note         string        `json:"note,omitempty"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Save writes the fixture to a file.
+func (f *Fixture) Save(path string) error {
+	cnt, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(cnt, '\n'), 0644)
+}
+
+// LoadFixture reads a fixture file written by Recorder.Save.
+func LoadFixture(path string) (*Fixture, error) {
+	cnt, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(cnt, &fixture); err != nil {
+		return nil, fmt.Errorf("reading fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// Recorder is an http.RoundTripper recording the calls going through
+// `Transport` into a Fixture:
+//
+//	rec := nodeostest.NewRecorder(nil)
+//	api := types.New("https://mainnet.eoscanada.com")
+//	api.HttpClient.Transport = rec
+//	...
+//	fixture := rec.Fixture()
+//	fixture.Note = "mainnet.eoscanada.com, nodeos v2.0.13, 2020-06-01"
+//	err := fixture.Save("testdata/get_block.json")
+//
+// Record the fixtures of regression tests from real nodes, noting
+// where they come from: replaying what the fake Node answers only
+// tests the library against itself.
+//
+// Wallet calls carrying private keys or passwords (see
+// types.IsSensitiveCall) are recorded with redacted bodies.
+type Recorder struct {
+	Transport http.RoundTripper
+
+	lock    sync.Mutex
+	fixture Fixture
+}
+
+// NewRecorder returns a recorder sending calls through `transport`,
+// or http.DefaultTransport if nil.
+func NewRecorder(transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{Transport: transport}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Endpoint:   endpointOf(req),
+		StatusCode: resp.StatusCode,
+	}
+	if isSensitive(interaction.Endpoint) {
+		reqBody, respBody = redactBody(reqBody), redactBody(respBody)
+	}
+	interaction.Request = requestJSON(reqBody)
+	if json.Valid(respBody) {
+		interaction.Response = json.RawMessage(respBody)
+	} else {
+		interaction.ResponseText = string(respBody)
+	}
+
+	r.lock.Lock()
+	r.fixture.Interactions = append(r.fixture.Interactions, interaction)
+	r.lock.Unlock()
+
+	return resp, nil
+}
+
+// Fixture returns the calls recorded so far.
+func (r *Recorder) Fixture() *Fixture {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return &Fixture{Interactions: append([]Interaction{}, r.fixture.Interactions...)}
+}
+
+// Save writes the calls recorded so far to a fixture file.
+func (r *Recorder) Save(path string) error {
+	return r.Fixture().Save(path)
+}
+
+// ErrUnexpectedCall is returned by a Replayer for calls it has no
+// recorded answer for.
+var ErrUnexpectedCall = errors.New("unexpected call")
+
+// Replayer is an http.RoundTripper answering calls from a Fixture,
+// without network.  A call is matched with the interactions recorded
+// for its endpoint and with the same JSON body (key order and spacing
+// don't matter).  Interactions are answered in the order they were
+// recorded: the same `get_info` call recorded twice gets both answers
+// in turn.
+//
+// In strict mode, each interaction is answered once, and any other
+// call fails with ErrUnexpectedCall.  Otherwise, the last answer of an
+// endpoint and body is repeated when they're all used, and a call
+// matching no body gets the first answer of its endpoint, which is
+// convenient for bodies changing from run to run, like transactions
+// with an expiration.
+type Replayer struct {
+	Strict bool
+
+	lock       sync.Mutex
+	fixture    *Fixture
+	used       []bool
+	unexpected []string
+}
+
+func NewReplayer(fixture *Fixture) *Replayer {
+	return &Replayer{
+		fixture: fixture,
+		used:    make([]bool, len(fixture.Interactions)),
+	}
+}
+
+// API returns an API answered by the replayer.
+func (r *Replayer) API() *types.API {
+	api := types.New("http://replay.invalid")
+	api.HttpClient = &http.Client{Transport: r}
+	return api
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := endpointOf(req)
+	body := normalizeJSON(requestJSON(reqBody))
+	if isSensitive(endpoint) {
+		body = nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	interaction := r.match(endpoint, body)
+	if interaction == nil {
+		call := endpoint
+		if len(reqBody) != 0 {
+			call = fmt.Sprintf("%s %s", endpoint, reqBody)
+		}
+		r.unexpected = append(r.unexpected, call)
+		return nil, fmt.Errorf("%s: %w", call, ErrUnexpectedCall)
+	}
+
+	respBody := []byte(interaction.Response)
+	if interaction.Response == nil {
+		respBody = []byte(interaction.ResponseText)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Replayer) match(endpoint string, body []byte) *Interaction {
+	lastMatch, firstOfEndpoint := -1, -1
+	for idx, interaction := range r.fixture.Interactions {
+		if interaction.Endpoint != endpoint {
+			continue
+		}
+		if firstOfEndpoint == -1 {
+			firstOfEndpoint = idx
+		}
+
+		recorded := normalizeJSON(interaction.Request)
+		if isSensitive(endpoint) {
+			recorded = nil
+		}
+		if !bytes.Equal(recorded, body) {
+			continue
+		}
+		if !r.used[idx] {
+			r.used[idx] = true
+			return &r.fixture.Interactions[idx]
+		}
+		lastMatch = idx
+	}
+
+	if r.Strict {
+		return nil
+	}
+	if lastMatch != -1 {
+		return &r.fixture.Interactions[lastMatch]
+	}
+	if firstOfEndpoint != -1 {
+		r.used[firstOfEndpoint] = true
+		return &r.fixture.Interactions[firstOfEndpoint]
+	}
+	return nil
+}
+
+// Unused returns the interactions that were never replayed.
+func (r *Replayer) Unused() []Interaction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var out []Interaction
+	for idx, used := range r.used {
+		if !used {
+			out = append(out, r.fixture.Interactions[idx])
+		}
+	}
+	return out
+}
+
+// Err reports the unexpected calls and, in strict mode, the
+// interactions left unused.  Check it at the end of a test.
+func (r *Replayer) Err() error {
+	var problems []string
+
+	r.lock.Lock()
+	for _, call := range r.unexpected {
+		problems = append(problems, "unexpected call "+call)
+	}
+	r.lock.Unlock()
+
+	if r.Strict {
+		for _, interaction := range r.Unused() {
+			problems = append(problems, "unused interaction "+interaction.Endpoint)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("replay: %s", strings.Join(problems, ", "))
+}
+
+// readBody reads a body and replaces it with an in-memory copy, for
+// the next reader.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	cnt, err := ioutil.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = ioutil.NopCloser(bytes.NewReader(cnt))
+	return cnt, nil
+}
+
+// endpointOf returns "chain/get_info" for ".../v1/chain/get_info".
+func endpointOf(req *http.Request) string {
+	path := req.URL.Path
+	if idx := strings.Index(path, "/v1/"); idx != -1 {
+		return path[idx+len("/v1/"):]
+	}
+	return strings.TrimPrefix(path, "/")
+}
+
+func isSensitive(endpoint string) bool {
+	parts := strings.SplitN(endpoint, "/", 2)
+	return len(parts) == 2 && types.IsSensitiveCall(parts[0], parts[1])
+}
+
+// requestJSON returns the body to keep in a fixture, strings being
+// used for bodies which are not JSON.
+func requestJSON(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if !json.Valid(body) {
+		body, _ = json.Marshal(string(body))
+	}
+	return json.RawMessage(body)
+}
+
+func redactBody(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	return []byte(`"<redacted>"`)
+}
+
+// normalizeJSON re-encodes a JSON body, sorting object keys and
+// removing spaces.
+func normalizeJSON(body []byte) []byte {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || bytes.Equal(body, []byte("null")) {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return body
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package nodeostest_test
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Akagi201/eosgo/nodeostest"
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Replay(t *testing.T) {
+	node := newTestNode(t)
+
+	rec := nodeostest.NewRecorder(nil)
+	api := node.API()
+	api.HttpClient = &http.Client{Transport: rec}
+
+	info, err := api.GetInfo()
+	require.NoError(t, err)
+	_, err = api.GetAccount("alice")
+	require.NoError(t, err)
+	_, err = api.GetAccount("nobody")
+	require.Error(t, err)
+	require.NoError(t, api.WalletImportKey("default", "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"))
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	require.NoError(t, rec.Save(path))
+	fixture, err := nodeostest.LoadFixture(path)
+	require.NoError(t, err)
+	require.Len(t, fixture.Interactions, 4)
+	assert.Equal(t, "chain/get_info", fixture.Interactions[0].Endpoint)
+	assert.NotContains(t, string(fixture.Interactions[3].Request), "5KQ", "private keys are redacted")
+
+	replayer := nodeostest.NewReplayer(fixture)
+	replayer.Strict = true
+	replay := replayer.API()
+
+	replayed, err := replay.GetInfo()
+	require.NoError(t, err)
+	assert.Equal(t, info.HeadBlockID, replayed.HeadBlockID)
+
+	account, err := replay.GetAccount("alice")
+	require.NoError(t, err)
+	assert.Len(t, account.Permissions, 2)
+
+	_, err = replay.GetAccount("nobody")
+	var apiErr *types.APIError
+	require.True(t, errors.As(err, &apiErr), "got %v", err)
+	assert.Equal(t, "account_query_exception", apiErr.ErrorStruct.Name)
+
+	require.NoError(t, replay.WalletImportKey("default", "5JzPj1VUD6m1p1yqv6jPmRGbbhhUvvFVdvF4pGUBovHcEWnNZv8"))
+	assert.NoError(t, replayer.Err())
+
+	// Each interaction is answered once in strict mode.
+	_, err = replay.GetInfo()
+	assert.True(t, errors.Is(err, nodeostest.ErrUnexpectedCall), "got %v", err)
+	assert.Error(t, replayer.Err())
+}
+
+func TestReplayer_Matching(t *testing.T) {
+	const bounds = `"scope": "", "table": "", "table_key": "", "lower_bound": "", "upper_bound": ""`
+	fixture := &nodeostest.Fixture{Interactions: []nodeostest.Interaction{
+		{Endpoint: "chain/get_table_rows", Request: []byte(`{"code": "a", "json": true, ` + bounds + `}`), StatusCode: 200, Response: []byte(`{"rows": [1], "more": false}`)},
+		{Endpoint: "chain/get_table_rows", Request: []byte(`{` + bounds + `, "json": true, "code": "b"}`), StatusCode: 200, Response: []byte(`{"rows": [2], "more": false}`)},
+		{Endpoint: "chain/get_info", StatusCode: 200, Response: []byte(`{"head_block_num": 1}`)},
+	}}
+
+	rows := func(api *types.API, code string) string {
+		resp, err := api.GetTableRows(types.GetTableRowsRequest{JSON: true, Code: code})
+		if err != nil {
+			return err.Error()
+		}
+		return string(resp.Rows)
+	}
+
+	replayer := nodeostest.NewReplayer(fixture)
+	replayer.Strict = true
+	api := replayer.API()
+	assert.Equal(t, "[2]", rows(api, "b"), "key order and spacing don't matter")
+	assert.Contains(t, rows(api, "c"), nodeostest.ErrUnexpectedCall.Error())
+
+	err := replayer.Err()
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "unused interaction chain/get_info"), err.Error())
+
+	// Otherwise, answers repeat, and unknown bodies get the first
+	// answer of their endpoint.
+	replayer = nodeostest.NewReplayer(fixture)
+	api = replayer.API()
+	assert.Equal(t, "[2]", rows(api, "b"))
+	assert.Equal(t, "[2]", rows(api, "b"))
+	assert.Equal(t, "[1]", rows(api, "c"))
+	assert.Len(t, replayer.Unused(), 1)
+
+	_, err = api.GetCurrencyStats("eosio.token", "EOS")
+	assert.True(t, errors.Is(err, nodeostest.ErrUnexpectedCall), "got %v", err)
+	assert.Error(t, replayer.Err())
+}
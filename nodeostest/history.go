@@ -0,0 +1,139 @@
+package nodeostest
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	"github.com/Akagi201/eosgo/ecc"
+	"github.com/Akagi201/eosgo/types"
+)
+
+// getActions serves the history of an account, like the
+// `history_plugin`: `pos` -1 is the last action, and a negative
+// `offset` goes back from `pos`.  Nothing executes, so the history of
+// an account only has the actions of its contract and the ones it
+// authorized, without notifications nor inline actions.
+func (n *Node) getActions(r *http.Request) (interface{}, *types.APIError) {
+	var params types.GetActionsRequest
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	actions := n.accountActions(params.AccountName)
+	pos := params.Pos
+	if pos == -1 {
+		pos = int64(len(actions)) - 1
+	}
+	start, end := pos, pos+params.Offset
+	if params.Offset < 0 {
+		start, end = pos+params.Offset, pos
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	resp := &types.GetActionsResp{
+		Actions:               []types.ActionResp{},
+		LastIrreversibleBlock: n.lib().BlockNum,
+	}
+	for seq := start; seq <= end && seq < int64(len(actions)); seq++ {
+		resp.Actions = append(resp.Actions, actions[seq])
+	}
+	return resp, nil
+}
+
+// accountActions lists the actions of `account` in the blocks, by
+// account sequence.
+func (n *Node) accountActions(account types.AccountName) []types.ActionResp {
+	var actions []types.ActionResp
+	var globalSeq int64
+	for _, block := range n.blocks {
+		for _, receipt := range block.Transactions {
+			record := n.txs[hex.EncodeToString(receipt.Transaction.ID)]
+			for _, action := range record.signedTx.Actions {
+				globalSeq++
+				if !involves(action, account) {
+					continue
+				}
+
+				trace := types.TransactionTrace{
+					Action:        action,
+					TransactionID: record.id,
+					InlineTraces:  []*types.TransactionTrace{},
+				}
+				trace.Receipt.Receiver = action.Account
+				trace.Receipt.GlobalSequence = globalSeq
+				actions = append(actions, types.ActionResp{
+					GlobalSeq:  globalSeq,
+					AccountSeq: int64(len(actions)),
+					BlockNum:   block.BlockNum,
+					BlockTime:  block.Timestamp,
+					Trace:      trace,
+				})
+			}
+		}
+	}
+	return actions
+}
+
+func involves(action *types.Action, account types.AccountName) bool {
+	if action.Account == account {
+		return true
+	}
+	for _, level := range action.Authorization {
+		if level.Actor == account {
+			return true
+		}
+	}
+	return false
+}
+
+// getKeyAccounts lists the accounts having the key in one of their
+// permissions.
+func (n *Node) getKeyAccounts(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		PublicKey ecc.PublicKey `json:"public_key"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &types.KeyAccountsResp{AccountNames: []types.AccountName{}}
+	for _, account := range n.sortedAccounts() {
+	permissions:
+		for _, perm := range account.Permissions {
+			for _, keyWeight := range perm.RequiredAuth.Keys {
+				if keyWeight.PublicKey.String() == params.PublicKey.String() {
+					resp.AccountNames = append(resp.AccountNames, account.AccountName)
+					break permissions
+				}
+			}
+		}
+	}
+	return resp, nil
+}
+
+// getControlledAccounts lists the accounts having the controlling
+// account in one of their permissions.
+func (n *Node) getControlledAccounts(r *http.Request) (interface{}, *types.APIError) {
+	var params struct {
+		ControllingAccount types.AccountName `json:"controlling_account"`
+	}
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &types.ControlledAccountsResp{ControlledAccounts: []types.AccountName{}}
+	for _, account := range n.sortedAccounts() {
+	permissions:
+		for _, perm := range account.Permissions {
+			for _, accountWeight := range perm.RequiredAuth.Accounts {
+				if accountWeight.Permission.Actor == params.ControllingAccount {
+					resp.ControlledAccounts = append(resp.ControlledAccounts, account.AccountName)
+					break permissions
+				}
+			}
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,298 @@
+// Package nodeostest provides an in-process fake `nodeos`, also
+// serving the `keosd` wallet endpoints, to test code using types.API
+// without a live node.
+//
+//	node := nodeostest.NewNode()
+//	defer node.Close()
+//
+//	node.CreateAccount("alice", key.PublicKey())
+//	node.Wallet.Add(wif)
+//
+//	api := node.API()
+//	resp, err := api.SignPushActions(token.NewTransfer("alice", "bob", quantity, ""))
+//
+// Pushed transactions are checked (TaPoS, expiration, duplicates and
+// signatures against the account permissions) but not executed: no
+// contract runs, so tables only hold what was seeded, and the history
+// of an account only has the actions of its contract and the ones it
+// authorized.
+//
+// The chain, history and wallet endpoints called by types.API are
+// served, except `get_producers`, `account_history/get_transactions`
+// and the wallet `create`, `create_key`, `remove_key` and
+// `set_timeout`.  The `producer` and `net` endpoints aren't either.
+// Unsupported endpoints answer with a 404.
+//
+// To test against real payloads instead, record the calls made to a
+// node with a Recorder, and replay them with a Replayer.
+package nodeostest
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Akagi201/eosgo/ecc"
+	"github.com/Akagi201/eosgo/types"
+)
+
+// DefaultChainID is the chain ID of new nodes.
+var DefaultChainID = func() types.SHA256Bytes {
+	h := sha256.Sum256([]byte("nodeostest"))
+	return h[:]
+}()
+
+// Node is a fake `nodeos` with an in-memory state.
+type Node struct {
+	*httptest.Server
+
+	ChainID types.SHA256Bytes
+
+	// LIBLag is the number of blocks between the head block and the
+	// last irreversible block, defaults to 0.
+	LIBLag uint32
+
+	// ManualBlocks keeps pushed transactions pending until ProduceBlock
+	// is called. By default, each push produces a block.
+	ManualBlocks bool
+
+	// Wallet holds the keys of the `keosd` endpoints.
+	Wallet *types.KeyBag
+
+	lock     sync.Mutex
+	blocks   []*types.BlockResp // blocks[0] is block 1
+	accounts map[types.AccountName]*types.AccountResp
+	abis     map[types.AccountName]*types.ABI
+	tables   map[tableKey][]tableRow
+	txs      map[string]*txRecord // by hex ID
+	pushed   []*types.PackedTransaction
+	pending  []types.TransactionReceipt
+}
+
+type tableKey struct {
+	code, scope, table string
+}
+
+type tableRow struct {
+	primaryKey uint64
+	row        interface{}
+}
+
+type txRecord struct {
+	id       types.SHA256Bytes
+	signedTx *types.SignedTransaction
+	blockNum uint32 // 0 while pending
+}
+
+// NewNode starts a node with a first block, and no account.
+func NewNode() *Node {
+	n := &Node{
+		ChainID:  DefaultChainID,
+		Wallet:   types.NewKeyBag(),
+		accounts: map[types.AccountName]*types.AccountResp{},
+		abis:     map[types.AccountName]*types.ABI{},
+		tables:   map[tableKey][]tableRow{},
+		txs:      map[string]*txRecord{},
+	}
+	n.ProduceBlock()
+	n.Server = httptest.NewServer(http.HandlerFunc(n.serveHTTP))
+	return n
+}
+
+// API returns an API talking to the node, signing through its wallet
+// endpoints.
+func (n *Node) API() *types.API {
+	api := types.New(n.URL)
+	api.SetSigner(types.NewWalletSigner(api, "default"))
+	return api
+}
+
+// CreateAccount creates an account with `owner` and `active`
+// permissions satisfied by any of `keys`.
+func (n *Node) CreateAccount(name types.AccountName, keys ...ecc.PublicKey) {
+	authority := types.Authority{Threshold: 1}
+	for _, key := range keys {
+		authority.Keys = append(authority.Keys, types.KeyWeight{PublicKey: key, Weight: 1})
+	}
+
+	n.SetAccount(&types.AccountResp{
+		AccountName: name,
+		Permissions: []types.Permission{
+			{PermName: "owner", RequiredAuth: authority},
+			{PermName: "active", Parent: "owner", RequiredAuth: authority},
+		},
+	})
+}
+
+// SetAccount creates or replaces an account, returned as is by
+// `get_account`. Its permissions are used to check signatures.
+func (n *Node) SetAccount(account *types.AccountResp) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.accounts[account.AccountName] = account
+}
+
+// SetABI sets the ABI returned by `get_abi` and `get_raw_abi` for
+// `account`.
+func (n *Node) SetABI(account types.AccountName, abi *types.ABI) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.abis[account] = abi
+}
+
+// SetTableRow creates or replaces a row of a table.  `row` is sent as
+// JSON, or binary-encoded when rows are requested with `json: false`.
+func (n *Node) SetTableRow(code, scope, table string, primaryKey uint64, row interface{}) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	key := tableKey{code, scope, table}
+	rows := n.tables[key]
+
+	idx := sort.Search(len(rows), func(i int) bool { return rows[i].primaryKey >= primaryKey })
+	if idx < len(rows) && rows[idx].primaryKey == primaryKey {
+		rows[idx].row = row
+		return
+	}
+
+	rows = append(rows, tableRow{})
+	copy(rows[idx+1:], rows[idx:])
+	rows[idx] = tableRow{primaryKey: primaryKey, row: row}
+	n.tables[key] = rows
+}
+
+// ProduceBlock produces a block with the pending transactions.
+func (n *Node) ProduceBlock() *types.BlockResp {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.produceBlock()
+}
+
+// Pushed returns all the transactions accepted by `push_transaction`.
+func (n *Node) Pushed() []*types.PackedTransaction {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return append([]*types.PackedTransaction{}, n.pushed...)
+}
+
+func (n *Node) produceBlock() *types.BlockResp {
+	blockTime := time.Now().UTC().Truncate(500 * time.Millisecond)
+
+	block := &types.BlockResp{}
+	block.Producer = "eosio"
+	block.Previous = make(types.SHA256Bytes, 32)
+	if head := n.head(); head != nil {
+		block.Previous = head.ID
+		if earliest := head.Timestamp.Add(500 * time.Millisecond); blockTime.Before(earliest) {
+			blockTime = earliest
+		}
+	}
+	block.Timestamp = types.BlockTimestamp{Time: blockTime}
+	block.Transactions = n.pending
+	if block.Transactions == nil {
+		block.Transactions = []types.TransactionReceipt{}
+	}
+	n.pending = nil
+
+	id, err := block.BlockID()
+	if err != nil {
+		panic("block headers always encode: " + err.Error())
+	}
+	block.ID = id
+	block.BlockNum = block.BlockNumber()
+	block.RefBlockPrefix = binary.LittleEndian.Uint32(id[8:16])
+
+	for _, receipt := range block.Transactions {
+		n.txs[hex.EncodeToString(receipt.Transaction.ID)].blockNum = block.BlockNum
+	}
+
+	n.blocks = append(n.blocks, block)
+	return block
+}
+
+// sortedAccounts returns the accounts ordered by name.
+func (n *Node) sortedAccounts() []*types.AccountResp {
+	accounts := make([]*types.AccountResp, 0, len(n.accounts))
+	for _, account := range n.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].AccountName < accounts[j].AccountName })
+	return accounts
+}
+
+func (n *Node) head() *types.BlockResp {
+	if len(n.blocks) == 0 {
+		return nil
+	}
+	return n.blocks[len(n.blocks)-1]
+}
+
+func (n *Node) lib() *types.BlockResp {
+	head := n.head()
+	if head.BlockNum <= n.LIBLag {
+		return n.blocks[0]
+	}
+	return n.blocks[head.BlockNum-n.LIBLag-1]
+}
+
+func (n *Node) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	var h handler
+	switch parts[0] {
+	case "chain":
+		h = n.chainHandlers()[parts[1]]
+	case "history":
+		h = n.historyHandlers()[parts[1]]
+	case "wallet":
+		h = n.walletHandlers()[parts[1]]
+	}
+	if h == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	out, apiErr := h(r)
+	w.Header().Set("Content-Type", "application/json")
+	if apiErr != nil {
+		w.WriteHeader(apiErr.Code)
+		json.NewEncoder(w).Encode(apiErr)
+		return
+	}
+	json.NewEncoder(w).Encode(out)
+}
+
+// newError builds the error envelope of `nodeos`.
+func newError(code int, name string, what string, details ...string) *types.APIError {
+	apiErr := &types.APIError{Code: 500, Message: "Internal Service Error"}
+	apiErr.ErrorStruct.Code = code
+	apiErr.ErrorStruct.Name = name
+	apiErr.ErrorStruct.What = what
+	apiErr.ErrorStruct.Details = []types.APIErrorDetail{}
+	for _, detail := range details {
+		apiErr.ErrorStruct.Details = append(apiErr.ErrorStruct.Details, types.APIErrorDetail{Message: detail})
+	}
+	return apiErr
+}
+
+func badRequest(err error) *types.APIError {
+	return newError(3200006, "invalid_http_request", "invalid http request", err.Error())
+}
@@ -0,0 +1,317 @@
+package nodeostest_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Akagi201/eosgo/ecc"
+	"github.com/Akagi201/eosgo/nodeostest"
+	"github.com/Akagi201/eosgo/token"
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestNode starts a node with `alice` and `bob`, only the key of
+// `alice` being in the wallet.
+func newTestNode(t *testing.T) *nodeostest.Node {
+	node := nodeostest.NewNode()
+	t.Cleanup(node.Close)
+
+	alice, err := ecc.NewRandomPrivateKey()
+	require.NoError(t, err)
+	bob, err := ecc.NewRandomPrivateKey()
+	require.NoError(t, err)
+
+	node.CreateAccount("alice", alice.PublicKey())
+	node.CreateAccount("bob", bob.PublicKey())
+	require.NoError(t, node.Wallet.Add(alice.String()))
+	return node
+}
+
+func TestNode_SignPushActions(t *testing.T) {
+	node := newTestNode(t)
+	api := node.API()
+
+	resp, err := api.SignPushActions(token.NewTransfer("alice", "bob", types.NewEOSAsset(10000), "hi"))
+	require.NoError(t, err)
+	require.Len(t, node.Pushed(), 1)
+
+	id, err := node.Pushed()[0].TransactionID()
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(id), resp.TransactionID)
+
+	info, err := api.GetInfo()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), info.HeadBlockNum)
+	assert.Equal(t, []byte(nodeostest.DefaultChainID), []byte(info.ChainID))
+
+	block, err := api.GetBlockByNum(2)
+	require.NoError(t, err)
+	require.Len(t, block.Transactions, 1)
+	assert.Equal(t, []byte(id), []byte(block.Transactions[0].Transaction.ID))
+
+	tx, err := api.GetTransaction(resp.TransactionID)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), tx.BlockNum)
+	assert.Len(t, tx.Transaction.Transaction.Signatures, 1)
+
+	_, err = api.PushTransaction(node.Pushed()[0])
+	assert.True(t, errors.Is(err, types.ErrTxDuplicate), "got %v", err)
+}
+
+func TestNode_ManualBlocks(t *testing.T) {
+	node := newTestNode(t)
+	node.ManualBlocks = true
+	node.LIBLag = 1
+	api := node.API()
+
+	resp, err := api.SignPushActions(token.NewTransfer("alice", "bob", types.NewEOSAsset(1), ""))
+	require.NoError(t, err)
+
+	_, err = api.GetTransaction(resp.TransactionID)
+	assert.True(t, errors.Is(err, types.ErrTxNotFound), "got %v", err)
+
+	node.ProduceBlock()
+	id, err := hex.DecodeString(resp.TransactionID)
+	require.NoError(t, err)
+	tracker := api.TrackTransactionID(id, time.Time{})
+	tracker.PollInterval = 10 * time.Millisecond
+	tracker.StartBlock = 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	state, err := tracker.Wait(ctx, types.TxExecuted)
+	require.NoError(t, err)
+	assert.Equal(t, types.TxExecuted, state.Status)
+	assert.Equal(t, uint32(2), state.BlockNum)
+
+	node.ProduceBlock()
+	state, err = tracker.Wait(ctx, types.TxIrreversible)
+	require.NoError(t, err)
+	assert.Equal(t, types.TxIrreversible, state.Status)
+}
+
+func TestNode_Rejections(t *testing.T) {
+	node := newTestNode(t)
+	api := node.API()
+
+	_, err := api.SignPushActions(token.NewTransfer("bob", "alice", types.NewEOSAsset(1), ""))
+	assert.True(t, errors.Is(err, types.ErrUnsatisfiedAuthorization), "got %v", err)
+
+	// Signing with the wrong key gets through the wallet, not the node.
+	keys, err := api.Signer.AvailableKeys()
+	require.NoError(t, err)
+	api.SetCustomGetRequiredKeys(func(tx *types.Transaction) ([]ecc.PublicKey, error) {
+		return keys, nil
+	})
+	_, err = api.SignPushActions(token.NewTransfer("bob", "alice", types.NewEOSAsset(1), ""))
+	assert.True(t, errors.Is(err, types.ErrUnsatisfiedAuthorization), "got %v", err)
+
+	info, err := api.GetInfo()
+	require.NoError(t, err)
+
+	tx := types.NewTransaction([]*types.Action{token.NewTransfer("alice", "bob", types.NewEOSAsset(1), "")}, &types.TxOptions{HeadBlockID: info.HeadBlockID})
+	tx.Expiration = types.JSONTime{Time: info.HeadBlockTime.Add(-time.Second)}
+	_, err = api.SignPushTransaction(tx, info.ChainID, types.CompressionNone)
+	assert.True(t, errors.Is(err, types.ErrExpiredTx), "got %v", err)
+
+	tx.Expiration = types.JSONTime{Time: time.Now().Add(2 * time.Hour)}
+	_, err = api.SignPushTransaction(tx, info.ChainID, types.CompressionNone)
+	assert.True(t, errors.Is(err, types.ErrTxExpirationTooFar), "got %v", err)
+
+	tx.Expiration = types.JSONTime{Time: time.Now().Add(time.Minute)}
+	tx.RefBlockPrefix++
+	_, err = api.SignPushTransaction(tx, info.ChainID, types.CompressionNone)
+	assert.True(t, errors.Is(err, types.ErrInvalidRefBlock), "got %v", err)
+
+	assert.Empty(t, node.Pushed())
+}
+
+type testRow struct {
+	ID    uint64            `json:"id"`
+	Owner types.AccountName `json:"owner"`
+}
+
+func TestNode_TableRows(t *testing.T) {
+	node := newTestNode(t)
+	for _, id := range []uint64{3, 1, 2} {
+		node.SetTableRow("contract", "contract", "rows", id, testRow{ID: id, Owner: "alice"})
+	}
+	node.SetTableRow("contract", "contract", "rows", 2, testRow{ID: 2, Owner: "bob"})
+	api := node.API()
+
+	for _, asJSON := range []bool{true, false} {
+		resp, err := api.GetTableRows(types.GetTableRowsRequest{
+			JSON: asJSON, Code: "contract", Scope: "contract", Table: "rows", Limit: 2,
+		})
+		require.NoError(t, err)
+		assert.True(t, resp.More)
+		assert.Equal(t, "3", resp.NextKey)
+
+		var rows []testRow
+		if asJSON {
+			require.NoError(t, resp.JSONToStructs(&rows))
+		} else {
+			require.NoError(t, resp.BinaryToStructs(&rows))
+		}
+		assert.Equal(t, []testRow{{1, "alice"}, {2, "bob"}}, rows)
+	}
+
+	iter := api.IterateTableRows(context.Background(), types.GetTableRowsRequest{
+		JSON: true, Code: "contract", Scope: "contract", Table: "rows", Limit: 1, Reverse: true,
+	})
+	var ids []uint64
+	for iter.Next() {
+		var rows []testRow
+		require.NoError(t, iter.Rows(&rows))
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+		}
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, []uint64{3, 2, 1}, ids)
+}
+
+func TestNode_Currency(t *testing.T) {
+	node := newTestNode(t)
+	supply, err := types.NewAsset("1000.0000 EOS")
+	require.NoError(t, err)
+	balance, err := types.NewAsset("12.5000 EOS")
+	require.NoError(t, err)
+
+	node.SetTableRow("eosio.token", "EOS", "stat", 0, types.CurrencyStats{Supply: supply, MaxSupply: supply, Issuer: "eosio"})
+	node.SetTableRow("eosio.token", "alice", "accounts", 0, map[string]interface{}{"balance": balance})
+
+	client := token.NewClient(node.API())
+	got, err := client.Balance(context.Background(), "eosio.token", "alice", "EOS")
+	require.NoError(t, err)
+	assert.Equal(t, balance, got)
+
+	got, err = client.Balance(context.Background(), "eosio.token", "bob", "EOS")
+	require.NoError(t, err)
+	assert.Equal(t, types.Asset{Symbol: supply.Symbol}, got)
+}
+
+func TestNode_TableByScope(t *testing.T) {
+	node := newTestNode(t)
+	for _, scope := range []string{"carol", "alice", "bob"} {
+		node.SetTableRow("eosio.token", scope, "accounts", 0, map[string]interface{}{"balance": "1.0000 EOS"})
+	}
+	node.SetTableRow("eosio.token", "bob", "stat", 0, map[string]interface{}{})
+	api := node.API()
+
+	var scopes []string
+	it := api.IterateTableByScope(context.Background(), types.GetTableByScopeRequest{Code: "eosio.token", Table: "accounts", Limit: 2})
+	for it.Next() {
+		for _, scope := range it.Scopes() {
+			assert.Equal(t, uint32(1), scope.Count)
+			scopes = append(scopes, string(scope.Scope))
+		}
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"alice", "bob", "carol"}, scopes)
+
+	resp, err := api.GetTableByScope(types.GetTableByScopeRequest{Code: "eosio.token", LowerBound: "bob", UpperBound: "bob"})
+	require.NoError(t, err)
+	require.Len(t, resp.Rows, 2)
+	assert.Equal(t, types.TableName("accounts"), resp.Rows[0].Table)
+	assert.Equal(t, types.TableName("stat"), resp.Rows[1].Table)
+}
+
+func TestNode_AccountLookups(t *testing.T) {
+	node := newTestNode(t)
+	key, err := ecc.NewRandomPrivateKey()
+	require.NoError(t, err)
+	node.CreateAccount("carol", key.PublicKey())
+	node.SetAccount(&types.AccountResp{
+		AccountName: "multisig",
+		Permissions: []types.Permission{{PermName: "active", RequiredAuth: types.Authority{
+			Threshold: 2,
+			Keys:      []types.KeyWeight{{PublicKey: key.PublicKey(), Weight: 1}},
+			Accounts:  []types.PermissionLevelWeight{{Permission: types.PermissionLevel{Actor: "alice", Permission: "active"}, Weight: 1}},
+		}}},
+	})
+	api := node.API()
+
+	accounts, err := api.GetKeyAccounts(key.PublicKey())
+	require.NoError(t, err)
+	assert.Equal(t, []types.AccountName{"carol", "multisig"}, accounts)
+
+	accounts, err = api.GetControlledAccounts("alice")
+	require.NoError(t, err)
+	assert.Equal(t, []types.AccountName{"multisig"}, accounts)
+
+	authorizers, err := api.GetAccountsByAuthorizers([]types.PermissionLevel{{Actor: "alice"}}, []ecc.PublicKey{key.PublicKey()})
+	require.NoError(t, err)
+	require.Len(t, authorizers, 4)
+	assert.Equal(t, types.PermissionName("owner"), authorizers[0].PermissionName)
+	assert.Equal(t, types.AccountName("multisig"), authorizers[2].AccountName)
+	assert.Equal(t, &types.PermissionLevel{Actor: "alice", Permission: "active"}, authorizers[2].AuthorizingAccount)
+	assert.Equal(t, uint32(2), authorizers[3].Threshold)
+	require.NotNil(t, authorizers[3].AuthorizingKey)
+	assert.Equal(t, key.PublicKey().String(), authorizers[3].AuthorizingKey.String())
+
+	abi := &types.ABI{Version: "eosio::abi/1.1"}
+	node.SetABI("carol", abi)
+	code, err := api.GetCode("carol")
+	require.NoError(t, err)
+	assert.Equal(t, *abi, code.ABI)
+	_, err = api.GetCode("nobody")
+	assert.Error(t, err)
+}
+
+func TestNode_Actions(t *testing.T) {
+	node := newTestNode(t)
+	api := node.API()
+	for _, memo := range []string{"one", "two", "three"} {
+		_, err := api.SignPushActions(token.NewTransfer("alice", "bob", types.NewEOSAsset(1), memo))
+		require.NoError(t, err)
+	}
+
+	var memos []string
+	it := api.IterateActions(context.Background(), "alice", true)
+	it.PageSize = 2
+	for it.Next() {
+		transfer, ok := it.Action().Trace.Action.Data.(*token.Transfer)
+		require.True(t, ok, "got %T", it.Action().Trace.Action.Data)
+		memos = append(memos, transfer.Memo)
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"three", "two", "one"}, memos)
+
+	resp, err := api.GetActions(types.GetActionsRequest{AccountName: "eosio.token", Pos: 1, Offset: 5})
+	require.NoError(t, err)
+	require.Len(t, resp.Actions, 2)
+	assert.Equal(t, int64(1), resp.Actions[0].AccountSeq)
+	assert.Equal(t, int64(2), resp.Actions[0].GlobalSeq)
+
+	resp, err = api.GetActions(types.GetActionsRequest{AccountName: "bob", Pos: -1, Offset: -10})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Actions, "only notified, not recorded")
+}
+
+func TestNode_ComputeTransaction(t *testing.T) {
+	node := newTestNode(t)
+	api := node.API()
+
+	estimate, err := api.EstimateResources([]*types.Action{token.NewTransfer("alice", "bob", types.NewEOSAsset(1), "")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100), estimate.CPUUsageMicroSeconds)
+	assert.NotZero(t, estimate.NetUsageWords)
+	assert.Empty(t, node.Pushed(), "not pushed")
+
+	// The signature is billed, without margin.
+	opts := &types.TxOptions{}
+	estimate.Apply(opts, 0)
+	_, err = api.SignPushActionsWithOpts([]*types.Action{token.NewTransfer("alice", "bob", types.NewEOSAsset(1), "")}, opts)
+	require.NoError(t, err)
+	require.Len(t, node.Pushed(), 1)
+
+	opts.MaxNetUsageWords = 1
+	_, err = api.SignPushActionsWithOpts([]*types.Action{token.NewTransfer("alice", "bob", types.NewEOSAsset(2), "")}, opts)
+	assert.True(t, errors.Is(err, types.ErrTxNetUsageExceeded), "got %v", err)
+}
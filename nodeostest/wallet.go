@@ -0,0 +1,112 @@
+package nodeostest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Akagi201/eosgo/ecc"
+	"github.com/Akagi201/eosgo/types"
+)
+
+// walletHandlers serve a single `default` wallet holding the keys of
+// Wallet, always unlocked.
+func (n *Node) walletHandlers() map[string]handler {
+	return map[string]handler{
+		"list_wallets":     n.listWallets,
+		"unlock":           n.walletNoop,
+		"lock":             n.walletNoop,
+		"lock_all":         n.walletNoop,
+		"open":             n.walletNoop,
+		"get_public_keys":  n.getPublicKeys,
+		"list_keys":        n.listKeys,
+		"import_key":       n.importKey,
+		"sign_transaction": n.signTransaction,
+		"sign_digest":      n.signDigest,
+	}
+}
+
+func walletError(name, what string, err error) *types.APIError {
+	return newError(3120000, name, what, err.Error())
+}
+
+func (n *Node) listWallets(r *http.Request) (interface{}, *types.APIError) {
+	return []string{"default *"}, nil
+}
+
+func (n *Node) walletNoop(r *http.Request) (interface{}, *types.APIError) {
+	return struct{}{}, nil
+}
+
+func (n *Node) getPublicKeys(r *http.Request) (interface{}, *types.APIError) {
+	out := []string{}
+	for _, key := range n.Wallet.Keys {
+		out = append(out, key.PublicKey().String())
+	}
+	return out, nil
+}
+
+func (n *Node) listKeys(r *http.Request) (interface{}, *types.APIError) {
+	out := []string{}
+	for _, key := range n.Wallet.Keys {
+		out = append(out, key.String())
+	}
+	return out, nil
+}
+
+func (n *Node) importKey(r *http.Request) (interface{}, *types.APIError) {
+	var params [2]string // wallet name, WIF key
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	if err := n.Wallet.Add(params[1]); err != nil {
+		return nil, walletError("key_exist_exception", "Key already exists", err)
+	}
+	return struct{}{}, nil
+}
+
+func (n *Node) signTransaction(r *http.Request) (interface{}, *types.APIError) {
+	var params [3]json.RawMessage // transaction, public keys, chain ID
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	tx := &types.SignedTransaction{}
+	var keys []ecc.PublicKey
+	var chainID types.SHA256Bytes
+	for i, v := range []interface{}{tx, &keys, &chainID} {
+		if err := json.Unmarshal(params[i], v); err != nil {
+			return nil, badRequest(err)
+		}
+	}
+
+	signed, err := n.Wallet.Sign(tx, chainID, keys...)
+	if err != nil {
+		return nil, walletError(string(types.ErrWalletMissingPubKey), "Missing public key", err)
+	}
+	return signed, nil
+}
+
+func (n *Node) signDigest(r *http.Request) (interface{}, *types.APIError) {
+	var params [2]string // hex digest, public key
+	if apiErr := decodeBody(r, &params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	digest, err := hex.DecodeString(params[0])
+	if err != nil {
+		return nil, badRequest(fmt.Errorf("digest: %w", err))
+	}
+	key, err := ecc.NewPublicKey(params[1])
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	sig, err := n.Wallet.SignDigest(digest, key)
+	if err != nil {
+		return nil, walletError(string(types.ErrWalletMissingPubKey), "Missing public key", err)
+	}
+	return sig.String(), nil
+}
@@ -0,0 +1,82 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Akagi201/eosgo/types"
+)
+
+// ErrTokenNotFound is returned when a contract has no token with the
+// requested symbol.
+var ErrTokenNotFound = errors.New("token not found")
+
+// Client reads token supplies and balances from `eosio.token`
+// compatible contracts.
+type Client struct {
+	API *types.API
+
+	// Contracts are the token contracts looked up by Balances.
+	Contracts []types.AccountName
+}
+
+// NewClient returns a Client reading balances on `contracts`, or on
+// `eosio.token` only if none is given.
+func NewClient(api *types.API, contracts ...types.AccountName) *Client {
+	if len(contracts) == 0 {
+		contracts = []types.AccountName{AN("eosio.token")}
+	}
+	return &Client{API: api, Contracts: contracts}
+}
+
+// Stats returns the supply, max supply and issuer of `symbol` (like
+// "EOS") on `contract`.
+func (c *Client) Stats(ctx context.Context, contract types.AccountName, symbol string) (*types.CurrencyStats, error) {
+	stats, err := c.API.GetCurrencyStatsContext(ctx, contract, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	for sym, stat := range stats {
+		if strings.EqualFold(sym, symbol) {
+			return &stat, nil
+		}
+	}
+	return nil, fmt.Errorf("%s on %s: %w", symbol, contract, ErrTokenNotFound)
+}
+
+// Balance returns the balance of `account` in `symbol` on `contract`.
+// An account without balance gets a zero Asset, with the precision of
+// the token.
+func (c *Client) Balance(ctx context.Context, contract, account types.AccountName, symbol string) (types.Asset, error) {
+	balances, err := c.API.GetCurrencyBalanceContext(ctx, account, symbol, contract)
+	if err != nil {
+		return types.Asset{}, err
+	}
+	if len(balances) > 0 {
+		return balances[0], nil
+	}
+
+	stats, err := c.Stats(ctx, contract, symbol)
+	if err != nil {
+		return types.Asset{}, err
+	}
+	return types.Asset{Symbol: stats.Supply.Symbol}, nil
+}
+
+// Balances returns all the balances of `account` over the Contracts
+// of the client, in the order of Contracts. Tokens sharing a symbol on
+// different contracts are distinct tokens, so they are not summed.
+func (c *Client) Balances(ctx context.Context, account types.AccountName) ([]types.Asset, error) {
+	var out []types.Asset
+	for _, contract := range c.Contracts {
+		balances, err := c.API.GetCurrencyBalanceContext(ctx, account, "", contract)
+		if err != nil {
+			return nil, fmt.Errorf("balances on %s: %w", contract, err)
+		}
+		out = append(out, balances...)
+	}
+	return out, nil
+}
@@ -0,0 +1,82 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTokenNode(t *testing.T) *httptest.Server {
+	balances := map[string][]string{
+		"eosio.token": {"12.5000 EOS", "3.00 SYS"},
+		"fake.token":  {"1000.0000 EOS"},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v1/chain/get_currency_stats":
+			if body["code"] == "eosio.token" && body["symbol"] == "EOS" {
+				w.Write([]byte(`{"EOS":{"supply":"1000000000.0000 EOS","max_supply":"10000000000.0000 EOS","issuer":"eosio"}}`))
+				return
+			}
+			w.Write([]byte(`{}`))
+		case "/v1/chain/get_currency_balance":
+			out := []string{}
+			if body["account"] == "alice" {
+				for _, balance := range balances[body["code"]] {
+					asset, err := types.NewAsset(balance)
+					require.NoError(t, err)
+					if body["symbol"] == "" || body["symbol"] == asset.Symbol.Symbol {
+						out = append(out, balance)
+					}
+				}
+			}
+			json.NewEncoder(w).Encode(out)
+		default:
+			t.Errorf("unexpected call to %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestClient(t *testing.T) {
+	server := newTestTokenNode(t)
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(types.New(server.URL), AN("eosio.token"), AN("fake.token"))
+
+	stats, err := client.Stats(ctx, AN("eosio.token"), "EOS")
+	require.NoError(t, err)
+	assert.Equal(t, "1000000000.0000 EOS", stats.Supply.String())
+	assert.Equal(t, "10000000000.0000 EOS", stats.MaxSupply.String())
+	assert.Equal(t, AN("eosio"), stats.Issuer)
+
+	_, err = client.Stats(ctx, AN("eosio.token"), "NOPE")
+	assert.True(t, errors.Is(err, ErrTokenNotFound))
+
+	balance, err := client.Balance(ctx, AN("eosio.token"), AN("alice"), "EOS")
+	require.NoError(t, err)
+	assert.Equal(t, "12.5000 EOS", balance.String())
+
+	balance, err = client.Balance(ctx, AN("eosio.token"), AN("bob"), "EOS")
+	require.NoError(t, err)
+	assert.Equal(t, "0.0000 EOS", balance.String())
+
+	balances, err := client.Balances(ctx, AN("alice"))
+	require.NoError(t, err)
+	var found []string
+	for _, balance := range balances {
+		found = append(found, balance.String())
+	}
+	assert.Equal(t, []string{"12.5000 EOS", "3.00 SYS", "1000.0000 EOS"}, found)
+}
@@ -2,6 +2,9 @@ package types
 
 // see: libraries/chain/contracts/abi_serializer.cpp:53...
 // see: libraries/chain/include/eosio/chain/contracts/types.hpp:100
+//
+// The fields are in the order of `abi_def`, so that the Encoder and
+// the Decoder give its binary form.  See MarshalBinary.
 type ABI struct {
 	Version          string            `json:"version"`
 	Types            []ABIType         `json:"types,omitempty"`
@@ -11,6 +14,20 @@ type ABI struct {
 	RicardianClauses []ClausePair      `json:"ricardian_clauses,omitempty"`
 	ErrorMessages    []ABIErrorMessage `json:"error_messages,omitempty"`
 	Extensions       []*Extension      `json:"abi_extensions,omitempty"`
+	Variants         []VariantDef      `json:"variants,omitempty" eos:"binary_extension"` // since `eosio::abi/1.1`
+}
+
+// MarshalBinary packs the ABI as `abi_def`, the form expected by the
+// `setabi` action and returned by `get_raw_abi`.
+func (a *ABI) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(a)
+}
+
+// UnmarshalBinary reads an ABI packed as `abi_def`.  Versions before
+// `eosio::abi/1.1` have no `variants`.  Sections added after
+// `variants` are ignored.
+func (a *ABI) UnmarshalBinary(data []byte) error {
+	return UnmarshalBinary(data, a)
 }
 
 type ABIType struct {
@@ -29,6 +46,12 @@ type FieldDef struct {
 	Type string `json:"type"`
 }
 
+// VariantDef defines a type which is one of `Types`.
+type VariantDef struct {
+	Name  string   `json:"name"`
+	Types []string `json:"types"`
+}
+
 type ActionDef struct {
 	Name              ActionName `json:"name"`
 	Type              string     `json:"type"`
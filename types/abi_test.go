@@ -0,0 +1,94 @@
+package types_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Akagi201/eosgo/nodeostest"
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hexString(s string) string {
+	return hex.EncodeToString([]byte{byte(len(s))}) + hex.EncodeToString([]byte(s))
+}
+
+var binaryABI = types.ABI{
+	Version:          "eosio::abi/1.1",
+	Types:            []types.ABIType{{NewTypeName: "account_name", Type: "name"}},
+	Structs:          []types.StructDef{{Name: "hi", Fields: []types.FieldDef{{Name: "user", Type: "account_name"}}}},
+	Actions:          []types.ActionDef{{Name: "hi", Type: "hi"}},
+	Tables:           []types.TableDef{{Name: "users", IndexType: "i64", KeyNames: []string{"id"}, KeyTypes: []string{"uint64"}, Type: "hi"}},
+	RicardianClauses: []types.ClausePair{{ID: "c", Body: "b"}},
+	ErrorMessages:    []types.ABIErrorMessage{{Code: 1, Message: "e"}},
+	Variants:         []types.VariantDef{{Name: "v", Types: []string{"name", "uint8"}}},
+}
+
+var binaryABIHex = strings.Join([]string{
+	hexString("eosio::abi/1.1"),
+	"01", hexString("account_name"), hexString("name"),
+	"01", hexString("hi"), hexString(""), "01", hexString("user"), hexString("account_name"),
+	"01", "000000000000806b", hexString("hi"), hexString(""),
+	"01", "00000000007c15d6", hexString("i64"), "01", hexString("id"), "01", hexString("uint64"), hexString("hi"),
+	"01", hexString("c"), hexString("b"),
+	"01", "0100000000000000", hexString("e"),
+	"00", // abi_extensions
+}, "")
+
+const binaryVariantsHex = "01" + "0176" + "02" + "046e616d65" + "0575696e7438"
+
+func TestABI_MarshalBinary(t *testing.T) {
+	bin, err := binaryABI.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, binaryABIHex+binaryVariantsHex, hex.EncodeToString(bin))
+
+	var abi types.ABI
+	require.NoError(t, abi.UnmarshalBinary(bin))
+	assert.Equal(t, binaryABI.Variants, abi.Variants)
+	assert.Equal(t, binaryABI.Tables, abi.Tables)
+
+	// `eosio::abi/1.0` has no variants.
+	old, err := hex.DecodeString(binaryABIHex)
+	require.NoError(t, err)
+	abi = types.ABI{}
+	require.NoError(t, abi.UnmarshalBinary(old))
+	assert.Equal(t, binaryABI.Structs, abi.Structs)
+	assert.Nil(t, abi.Variants)
+}
+
+func TestNewSetABI(t *testing.T) {
+	action := types.NewSetABI("alice", binaryABI)
+	assert.Equal(t, []types.PermissionLevel{{Actor: "alice", Permission: "active"}}, action.Authorization)
+
+	data, err := types.MarshalBinary(action.ActionData.Data)
+	require.NoError(t, err)
+	require.Equal(t, 131*2, len(binaryABIHex+binaryVariantsHex))
+	assert.Equal(t, "0000000000855c34"+"8301"+binaryABIHex+binaryVariantsHex, hex.EncodeToString(data), "account, then the ABI as bytes")
+
+	var setABI types.SetABI
+	require.NoError(t, types.UnmarshalBinary(data, &setABI))
+	assert.Equal(t, types.AccountName("alice"), setABI.Account)
+	assert.Equal(t, binaryABI.Actions, setABI.ABI.Actions)
+}
+
+func TestAPI_GetRawABI(t *testing.T) {
+	node := nodeostest.NewNode()
+	defer node.Close()
+	abi := binaryABI
+	node.SetABI("alice", &abi)
+
+	resp, err := node.API().GetRawABI("alice")
+	require.NoError(t, err)
+	decoded, err := resp.DecodeABI()
+	require.NoError(t, err)
+	assert.Equal(t, binaryABI.Structs, decoded.Structs)
+	assert.Len(t, resp.ABIHash, 32)
+
+	// nodeos may leave out the base64 padding.
+	var raw types.GetRawABIResp
+	require.NoError(t, json.Unmarshal([]byte(`{"account_name":"alice","abi":"DmVvc2lvOjphYmkvMS4x"}`), &raw))
+	assert.Equal(t, "0e656f73696f3a3a6162692f312e31", hex.EncodeToString(raw.ABI))
+}
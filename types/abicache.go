@@ -0,0 +1,228 @@
+package types
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ABICache caches the ABIs of accounts, to decode their actions and
+// table rows with ABI.DecodeAction and ABI.DecodeTableRow.  Missing
+// ABIs are fetched when first needed.  Feed it the blocks and traces
+// you process, with ObserveBlock and ObserveTrace, so it drops the ABIs
+// which changed.  It's safe for concurrent use.
+type ABICache struct {
+	// RawABI fetches ABIs with `get_raw_abi` instead of `get_abi`.
+	// ABIs fetched again after being invalidated are then only
+	// decoded when their hash changed.
+	RawABI bool
+
+	api *API
+
+	lock        sync.Mutex
+	entries     map[AccountName]*abiCacheEntry
+	generations map[AccountName]uint64
+	sequences   map[AccountName]int64
+}
+
+type abiCacheEntry struct {
+	abi   *ABI
+	hash  string // `abi_hash` of `get_raw_abi`
+	stale bool
+}
+
+// NewABICache returns an empty cache fetching ABIs through `api`.  A
+// nil `api` gives an offline cache, holding only the ABIs given to Set
+// and SeedSystemABIs.
+func NewABICache(api *API) *ABICache {
+	return &ABICache{
+		api:         api,
+		entries:     map[AccountName]*abiCacheEntry{},
+		generations: map[AccountName]uint64{},
+		sequences:   map[AccountName]int64{},
+	}
+}
+
+// SeedSystemABIs caches the ABIs of the system contracts, see
+// SystemABIs.  They are invalidated like the others, so offline
+// caches should not observe the blocks of chains with other system
+// contracts.
+func (c *ABICache) SeedSystemABIs() {
+	for account, abi := range SystemABIs() {
+		c.Set(account, abi)
+	}
+}
+
+// Set caches the ABI of `account`.
+func (c *ABICache) Set(account AccountName, abi *ABI) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries[account] = &abiCacheEntry{abi: abi}
+}
+
+// Invalidate makes the next GetABI of `account` fetch its ABI again.
+func (c *ABICache) Invalidate(account AccountName) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.invalidate(account)
+}
+
+func (c *ABICache) invalidate(account AccountName) {
+	c.generations[account]++
+	if entry := c.entries[account]; entry != nil && !entry.stale {
+		c.entries[account] = &abiCacheEntry{abi: entry.abi, hash: entry.hash, stale: true}
+	}
+}
+
+// GetABI returns the ABI of `account`, from the cache or from the
+// chain.  The ABI is shared and must not be modified.  Accounts
+// without ABI give an ErrNotFound error.
+func (c *ABICache) GetABI(account AccountName) (*ABI, error) {
+	return c.GetABIContext(context.Background(), account)
+}
+
+func (c *ABICache) GetABIContext(ctx context.Context, account AccountName) (*ABI, error) {
+	c.lock.Lock()
+	entry := c.entries[account]
+	generation := c.generations[account]
+	c.lock.Unlock()
+
+	if entry == nil || entry.stale {
+		if c.api == nil {
+			return nil, fmt.Errorf("ABI of %s not cached, and no API to fetch it", account)
+		}
+
+		fetched, err := c.fetch(ctx, account, entry)
+		if err != nil {
+			return nil, fmt.Errorf("fetching ABI of %s: %w", account, err)
+		}
+
+		// Invalidated while fetching, the ABI may predate the change.
+		c.lock.Lock()
+		fetched.stale = c.generations[account] != generation
+		c.entries[account] = fetched
+		c.lock.Unlock()
+		entry = fetched
+	}
+
+	if entry.abi.Version == "" {
+		return nil, fmt.Errorf("account %s has no ABI: %w", account, ErrNotFound)
+	}
+	return entry.abi, nil
+}
+
+func (c *ABICache) fetch(ctx context.Context, account AccountName, previous *abiCacheEntry) (*abiCacheEntry, error) {
+	if !c.RawABI {
+		resp, err := c.api.GetABIContext(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		return &abiCacheEntry{abi: &resp.ABI}, nil
+	}
+
+	resp, err := c.api.GetRawABIContext(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	hash := hex.EncodeToString(resp.ABIHash)
+	if previous != nil && previous.hash == hash {
+		if c.api.Debug {
+			c.api.logger().Debug("ABI unchanged", "account", account, "abi_hash", hash)
+		}
+		return &abiCacheEntry{abi: previous.abi, hash: hash}, nil
+	}
+
+	abi := &ABI{}
+	if len(resp.ABI) != 0 {
+		if abi, err = resp.DecodeABI(); err != nil {
+			return nil, err
+		}
+	}
+	return &abiCacheEntry{abi: abi, hash: hash}, nil
+}
+
+// ObserveBlock invalidates the ABIs set by the `setabi` actions of
+// `block`.  Transactions that can't be unpacked don't stop the others
+// from being observed: they are reported together in the error.
+func (c *ABICache) ObserveBlock(block *BlockResp) error {
+	var problems []string
+	for _, receipt := range block.Transactions {
+		if receipt.Transaction.Packed == nil {
+			continue
+		}
+		signedTx, err := receipt.Transaction.Packed.Unpack()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("transaction %s: %s", hex.EncodeToString(receipt.Transaction.ID), err))
+			continue
+		}
+		for _, action := range signedTx.ContextFreeActions {
+			c.ObserveAction(action)
+		}
+		for _, action := range signedTx.Actions {
+			c.ObserveAction(action)
+		}
+	}
+
+	if len(problems) != 0 {
+		return fmt.Errorf("block %d: %s", block.BlockNum, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ObserveTrace invalidates the ABIs set by the `setabi` actions of
+// `trace` and of its inline traces, and those of receivers whose
+// `abi_sequence` moved since their previous trace.  The first
+// `abi_sequence` of a receiver can't tell whether its cached ABI is
+// still current, so that ABI is fetched again once to be safe.
+func (c *ABICache) ObserveTrace(trace *TransactionTrace) {
+	if trace.Action != nil {
+		c.ObserveAction(trace.Action)
+	}
+
+	if receiver, sequence := trace.Receipt.Receiver, trace.Receipt.ABISequence; receiver != "" && sequence != 0 {
+		c.lock.Lock()
+		if previous := c.sequences[receiver]; sequence > previous {
+			if previous != 0 || c.entries[receiver] != nil {
+				c.invalidate(receiver)
+			}
+			c.sequences[receiver] = sequence
+		}
+		c.lock.Unlock()
+	}
+
+	for _, inline := range trace.InlineTraces {
+		c.ObserveTrace(inline)
+	}
+}
+
+// ObserveAction invalidates the ABI set by `action`, when it's an
+// `eosio::setabi` action.
+func (c *ABICache) ObserveAction(action *Action) {
+	if action.Account != AN("eosio") || action.Name != ActN("setabi") {
+		return
+	}
+
+	var account AccountName
+	switch data := action.ActionData.Data.(type) {
+	case SetABI:
+		account = data.Account
+	case *SetABI:
+		account = data.Account
+	case map[string]interface{}:
+		name, _ := data["account"].(string)
+		account = AccountName(name)
+	}
+	if account == "" && len(action.ActionData.HexData) >= 8 {
+		if n, err := NewDecoder(action.ActionData.HexData).ReadUint64(); err == nil {
+			account = AccountName(NameToString(n))
+		}
+	}
+
+	if account != "" {
+		c.Invalidate(account)
+	}
+}
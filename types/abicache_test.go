@@ -0,0 +1,138 @@
+package types_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Akagi201/eosgo/token"
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingTransport counts the calls to `endpoint`.
+type countingTransport struct {
+	next     http.RoundTripper
+	endpoint string
+	calls    int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, c.endpoint) {
+		atomic.AddInt32(&c.calls, 1)
+	}
+	return c.next.RoundTrip(req)
+}
+
+func TestABICache(t *testing.T) {
+	for _, raw := range []bool{false, true} {
+		node, api := newSubmitNode(t)
+		abi := binaryABI
+		node.SetABI("alice", &abi)
+
+		endpoint := "/get_abi"
+		if raw {
+			endpoint = "/get_raw_abi"
+		}
+		transport := &countingTransport{next: api.HttpClient.Transport, endpoint: endpoint}
+		api.HttpClient.Transport = transport
+
+		cache := types.NewABICache(api)
+		cache.RawABI = raw
+		first, err := cache.GetABI("alice")
+		require.NoError(t, err)
+		assert.Equal(t, binaryABI.Structs, first.Structs)
+		_, err = cache.GetABI("alice")
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&transport.calls), "raw: %t", raw)
+
+		_, err = cache.GetABI("bob")
+		assert.True(t, errors.Is(err, types.ErrNotFound), "got %v", err)
+
+		// `setabi` in a block.
+		_, err = api.SignPushActions(types.NewSetABI("alice", binaryABI))
+		require.NoError(t, err)
+		require.NoError(t, cache.ObserveBlock(node.ProduceBlock()))
+		again, err := cache.GetABI("alice")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&transport.calls))
+		if raw {
+			assert.True(t, first == again, "same hash, not decoded again")
+		}
+
+		// `abi_sequence` of traces: the first one may be newer than the
+		// cached ABI, then only when moving forward.
+		trace := &types.TransactionTrace{}
+		trace.Receipt.Receiver = "alice"
+		for _, step := range []struct {
+			sequence int64
+			calls    int32
+		}{{3, 3}, {3, 3}, {2, 3}, {4, 4}} {
+			trace.Receipt.ABISequence = step.sequence
+			cache.ObserveTrace(&types.TransactionTrace{InlineTraces: []*types.TransactionTrace{trace}})
+			_, err = cache.GetABI("alice")
+			require.NoError(t, err)
+			assert.Equal(t, step.calls, atomic.LoadInt32(&transport.calls), "abi_sequence %d", step.sequence)
+		}
+	}
+}
+
+func TestABICache_ObserveBlock(t *testing.T) {
+	cache := types.NewABICache(nil)
+	abi := binaryABI
+	cache.Set("alice", &abi)
+
+	tx := types.NewTransaction([]*types.Action{types.NewSetABI("alice", binaryABI)}, &types.TxOptions{HeadBlockID: make(types.SHA256Bytes, 32)})
+	packed, err := types.NewSignedTransaction(tx).Pack(types.CompressionNone)
+	require.NoError(t, err)
+
+	block := &types.BlockResp{}
+	block.BlockNum = 10
+	block.Transactions = []types.TransactionReceipt{
+		{Transaction: types.TransactionWithID{ID: make(types.SHA256Bytes, 32), Packed: &types.PackedTransaction{Compression: types.CompressionZlib, PackedTransaction: []byte{0x01}}}},
+		{Transaction: types.TransactionWithID{Packed: packed}},
+	}
+	err = cache.ObserveBlock(block)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "block 10: transaction 0000")
+
+	_, err = cache.GetABI("alice")
+	assert.Error(t, err, "the setabi after the broken transaction is observed")
+}
+
+func TestABICache_SystemABIs(t *testing.T) {
+	for account, abi := range types.SystemABIs() {
+		assert.NoError(t, abi.Validate(), "%s", account)
+	}
+
+	cache := types.NewABICache(nil)
+	cache.SeedSystemABIs()
+
+	abi, err := cache.GetABI("eosio.token")
+	require.NoError(t, err)
+	bin, err := abi.EncodeAction("transfer", map[string]interface{}{
+		"from": "alice", "to": "bob", "quantity": "1.0000 EOS", "memo": "hi",
+	})
+	require.NoError(t, err)
+	expected, err := types.MarshalBinary(token.Transfer{From: "alice", To: "bob", Quantity: types.NewEOSAsset(10000), Memo: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(bin))
+
+	abi, err = cache.GetABI("eosio")
+	require.NoError(t, err)
+	setABI := types.NewSetABI("alice", binaryABI)
+	bin, err = types.MarshalBinary(setABI.ActionData.Data)
+	require.NoError(t, err)
+	data, err := abi.DecodeAction("setabi", bin)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", data["account"])
+
+	cache.ObserveAction(setABI)
+	cache.ObserveAction(types.NewSetABI("eosio.token", binaryABI))
+	_, err = cache.GetABI("eosio.token")
+	assert.Error(t, err, "invalidated, and offline")
+}
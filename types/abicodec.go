@@ -0,0 +1,966 @@
+package types
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Akagi201/eosgo/ecc"
+)
+
+// EncodeAction packs the arguments of `action` with the ABI, like
+// `abi_json_to_bin` does on a node.  `data` is the JSON nodeos
+// expects, as a json.RawMessage, or as any value marshalling to it
+// (a map[string]interface{}, a struct with `json` tags...).
+func (a *ABI) EncodeAction(action ActionName, data interface{}) ([]byte, error) {
+	def := a.actionDef(action)
+	if def == nil {
+		return nil, fmt.Errorf("action %q not found in ABI", action)
+	}
+	return a.EncodeType(def.Type, data)
+}
+
+// EncodeType packs `data` as the type `typeName` of the ABI, or as a
+// built-in type.  See EncodeAction.
+func (a *ABI) EncodeType(typeName string, data interface{}) ([]byte, error) {
+	value, err := toJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := newABICodec(a).encode(NewEncoder(buf), typeName, value, 0); err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", typeName, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// NewActionData returns the ActionData of `action` packed with the
+// ABI.  See EncodeAction.
+func (a *ABI) NewActionData(action ActionName, data interface{}) (ActionData, error) {
+	bin, err := a.EncodeAction(action, data)
+	if err != nil {
+		return ActionData{}, err
+	}
+	return ActionData{HexData: bin}, nil
+}
+
+// DecodeAction unpacks the arguments of `action`, like
+// `abi_bin_to_json` does on a node.  See DecodeType for the values.
+func (a *ABI) DecodeAction(action ActionName, data []byte) (map[string]interface{}, error) {
+	def := a.actionDef(action)
+	if def == nil {
+		return nil, fmt.Errorf("action %q not found in ABI", action)
+	}
+	return a.decodeObject(def.Type, data)
+}
+
+// DecodeTableRow unpacks a row of `table`, as returned by
+// `get_table_rows` with `json: false`.
+func (a *ABI) DecodeTableRow(table TableName, data []byte) (map[string]interface{}, error) {
+	for _, def := range a.Tables {
+		if def.Name == table {
+			return a.decodeObject(def.Type, data)
+		}
+	}
+	return nil, fmt.Errorf("table %q not found in ABI", table)
+}
+
+// DecodeType unpacks `data` as the type `typeName`.  Values are
+// decoded as nodeos formats them in JSON: structs as
+// map[string]interface{}, arrays as []interface{}, variants as a
+// `[type, value]` pair, optionals as nil when absent, integers as
+// Go integers (128 bits ones as decimal strings), names, assets,
+// keys, checksums, bytes and times as strings.  All of `data` must be
+// used.
+func (a *ABI) DecodeType(typeName string, data []byte) (interface{}, error) {
+	decoder := NewDecoder(data)
+	value, err := newABICodec(a).decode(decoder, typeName, 0)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", typeName, err)
+	}
+	if decoder.Remaining() != 0 {
+		return nil, fmt.Errorf("decoding %s: %d bytes left", typeName, decoder.Remaining())
+	}
+	return value, nil
+}
+
+func (a *ABI) decodeObject(typeName string, data []byte) (map[string]interface{}, error) {
+	value, err := a.DecodeType(typeName, data)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("decoding %s: not a struct", typeName)
+	}
+	return obj, nil
+}
+
+func (a *ABI) actionDef(action ActionName) *ActionDef {
+	for idx := range a.Actions {
+		if a.Actions[idx].Name == action {
+			return &a.Actions[idx]
+		}
+	}
+	return nil
+}
+
+// toJSONValue turns `data` into what encoding/json decodes into an
+// interface{}, with json.Number for numbers.
+func toJSONValue(data interface{}) (interface{}, error) {
+	cnt, ok := data.(json.RawMessage)
+	if !ok {
+		var err error
+		if cnt, err = json.Marshal(data); err != nil {
+			return nil, err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(cnt))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// maxABIDepth is the nesting limit of nodeos, which also guards
+// against recursive ABIs.
+const maxABIDepth = 32
+
+type abiCodec struct {
+	typedefs map[string]string
+	structs  map[string]*StructDef
+	variants map[string]*VariantDef
+}
+
+var extendedAssetDef = StructDef{
+	Name:   "extended_asset",
+	Fields: []FieldDef{{Name: "quantity", Type: "asset"}, {Name: "contract", Type: "name"}},
+}
+
+func newABICodec(a *ABI) *abiCodec {
+	c := &abiCodec{
+		typedefs: map[string]string{},
+		structs:  map[string]*StructDef{"extended_asset": &extendedAssetDef},
+		variants: map[string]*VariantDef{},
+	}
+	for _, typedef := range a.Types {
+		c.typedefs[typedef.NewTypeName] = typedef.Type
+	}
+	for idx := range a.Structs {
+		c.structs[a.Structs[idx].Name] = &a.Structs[idx]
+	}
+	for idx := range a.Variants {
+		c.variants[a.Variants[idx].Name] = &a.Variants[idx]
+	}
+	return c
+}
+
+// resolve follows the `types` aliases.
+func (c *abiCodec) resolve(typeName string) (string, error) {
+	resolved := typeName
+	for i := 0; i < maxABIDepth; i++ {
+		target, ok := c.typedefs[resolved]
+		if !ok {
+			return resolved, nil
+		}
+		resolved = target
+	}
+	return "", fmt.Errorf("type %q: circular alias", typeName)
+}
+
+func (c *abiCodec) encode(e *Encoder, typeName string, v interface{}, depth int) error {
+	if depth > maxABIDepth {
+		return fmt.Errorf("type %q: nested too deep", typeName)
+	}
+	typeName, err := c.resolve(typeName)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case strings.HasSuffix(typeName, "$"):
+		return c.encode(e, strings.TrimSuffix(typeName, "$"), v, depth+1)
+	case strings.HasSuffix(typeName, "?"):
+		if v == nil {
+			return e.WriteBool(false)
+		}
+		if err := e.WriteBool(true); err != nil {
+			return err
+		}
+		return c.encode(e, strings.TrimSuffix(typeName, "?"), v, depth+1)
+	case strings.HasSuffix(typeName, "[]"):
+		items, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected an array for %s, got %s", typeName, jsonKind(v))
+		}
+		if err := e.WriteUVarInt(len(items)); err != nil {
+			return err
+		}
+		for idx, item := range items {
+			if err := c.encode(e, strings.TrimSuffix(typeName, "[]"), item, depth+1); err != nil {
+				return fmt.Errorf("[%d]: %w", idx, err)
+			}
+		}
+		return nil
+	}
+
+	if builtin, ok := abiBuiltins[typeName]; ok {
+		return builtin.encode(e, v)
+	}
+	if def, ok := c.structs[typeName]; ok {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected an object for %s, got %s", typeName, jsonKind(v))
+		}
+		return c.encodeFields(e, def, obj, depth+1)
+	}
+	if def, ok := c.variants[typeName]; ok {
+		return c.encodeVariant(e, def, v, depth+1)
+	}
+	return fmt.Errorf("unknown type %q", typeName)
+}
+
+func (c *abiCodec) encodeFields(e *Encoder, def *StructDef, obj map[string]interface{}, depth int) error {
+	if def.Base != "" {
+		base, err := c.baseOf(def, depth)
+		if err != nil {
+			return err
+		}
+		if err := c.encodeFields(e, base, obj, depth+1); err != nil {
+			return err
+		}
+	}
+
+	extensionsMissing := false
+	for _, field := range def.Fields {
+		value, present := obj[field.Name]
+		if strings.HasSuffix(field.Type, "$") {
+			if !present {
+				extensionsMissing = true
+				continue
+			}
+			if extensionsMissing {
+				return fmt.Errorf("%s.%s: binary extension present after missing ones", def.Name, field.Name)
+			}
+		} else if !present && !strings.HasSuffix(field.Type, "?") {
+			return fmt.Errorf("%s: missing field %q", def.Name, field.Name)
+		}
+
+		if err := c.encode(e, field.Type, value, depth); err != nil {
+			return fmt.Errorf("%s.%s: %w", def.Name, field.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *abiCodec) encodeVariant(e *Encoder, def *VariantDef, v interface{}, depth int) error {
+	pair, ok := v.([]interface{})
+	if !ok || len(pair) != 2 {
+		return fmt.Errorf("expected a [type, value] pair for variant %s, got %s", def.Name, jsonKind(v))
+	}
+	typeName, ok := pair[0].(string)
+	if !ok {
+		return fmt.Errorf("expected a type name first in variant %s, got %s", def.Name, jsonKind(pair[0]))
+	}
+
+	for idx, candidate := range def.Types {
+		if candidate == typeName {
+			if err := e.WriteUVarInt(idx); err != nil {
+				return err
+			}
+			return c.encode(e, typeName, pair[1], depth)
+		}
+	}
+	return fmt.Errorf("type %q is not part of variant %s", typeName, def.Name)
+}
+
+func (c *abiCodec) decode(d *Decoder, typeName string, depth int) (interface{}, error) {
+	if depth > maxABIDepth {
+		return nil, fmt.Errorf("type %q: nested too deep", typeName)
+	}
+	typeName, err := c.resolve(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.HasSuffix(typeName, "$"):
+		return c.decode(d, strings.TrimSuffix(typeName, "$"), depth+1)
+	case strings.HasSuffix(typeName, "?"):
+		present, err := d.ReadBool()
+		if err != nil || !present {
+			return nil, err
+		}
+		return c.decode(d, strings.TrimSuffix(typeName, "?"), depth+1)
+	case strings.HasSuffix(typeName, "[]"):
+		l, err := d.ReadUvarint()
+		if err != nil {
+			return nil, err
+		}
+		itemType := strings.TrimSuffix(typeName, "[]")
+		var items []interface{}
+		if !c.zeroSize(itemType, depth+1) {
+			if l > uint64(d.Remaining()) {
+				return nil, fmt.Errorf("%s of %d items, only %d bytes remaining", typeName, l, d.Remaining())
+			}
+			items = make([]interface{}, 0, l)
+		}
+		for idx := 0; idx < int(l); idx++ {
+			item, err := c.decode(d, itemType, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", idx, err)
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	if builtin, ok := abiBuiltins[typeName]; ok {
+		return builtin.decode(d)
+	}
+	if def, ok := c.structs[typeName]; ok {
+		obj := map[string]interface{}{}
+		return obj, c.decodeFields(d, def, obj, depth+1)
+	}
+	if def, ok := c.variants[typeName]; ok {
+		idx, err := d.ReadUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if idx >= uint64(len(def.Types)) {
+			return nil, fmt.Errorf("variant %s has no type #%d", def.Name, idx)
+		}
+		value, err := c.decode(d, def.Types[idx], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{def.Types[idx], value}, nil
+	}
+	return nil, fmt.Errorf("unknown type %q", typeName)
+}
+
+func (c *abiCodec) decodeFields(d *Decoder, def *StructDef, obj map[string]interface{}, depth int) error {
+	if def.Base != "" {
+		base, err := c.baseOf(def, depth)
+		if err != nil {
+			return err
+		}
+		if err := c.decodeFields(d, base, obj, depth+1); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range def.Fields {
+		if strings.HasSuffix(field.Type, "$") && d.Remaining() == 0 {
+			return nil
+		}
+
+		value, err := c.decode(d, field.Type, depth)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", def.Name, field.Name, err)
+		}
+		obj[field.Name] = value
+	}
+	return nil
+}
+
+// zeroSize tells whether `typeName` may be encoded with no bytes at
+// all, like empty structs.
+func (c *abiCodec) zeroSize(typeName string, depth int) bool {
+	if depth > maxABIDepth {
+		return false
+	}
+	typeName, err := c.resolve(typeName)
+	if err != nil {
+		return false
+	}
+	if strings.HasSuffix(typeName, "$") {
+		return true
+	}
+
+	def, ok := c.structs[typeName]
+	if !ok {
+		// Built-in types, optionals, arrays and variants take a byte
+		// at least.
+		return false
+	}
+	if def.Base != "" {
+		base, err := c.baseOf(def, depth)
+		if err != nil || !c.zeroSize(base.Name, depth+1) {
+			return false
+		}
+	}
+	for _, field := range def.Fields {
+		if !c.zeroSize(field.Type, depth+1) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *abiCodec) baseOf(def *StructDef, depth int) (*StructDef, error) {
+	if depth > maxABIDepth {
+		return nil, fmt.Errorf("struct %q: too many bases", def.Name)
+	}
+	baseName, err := c.resolve(def.Base)
+	if err != nil {
+		return nil, err
+	}
+	base, ok := c.structs[baseName]
+	if !ok {
+		return nil, fmt.Errorf("base %q of struct %q not found", def.Base, def.Name)
+	}
+	return base, nil
+}
+
+// jsonKind describes a decoded JSON value in errors.
+func jsonKind(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case json.Number:
+		return "number " + string(v)
+	case bool:
+		return fmt.Sprintf("%t", v)
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+type abiBuiltin struct {
+	encode func(e *Encoder, v interface{}) error
+	decode func(d *Decoder) (interface{}, error)
+}
+
+// abiBuiltins are the types known to nodeos without being defined in
+// ABIs.  See libraries/chain/abi_serializer.cpp
+var abiBuiltins = map[string]abiBuiltin{
+	"bool": {
+		func(e *Encoder, v interface{}) error {
+			b, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("expected a bool, got %s", jsonKind(v))
+			}
+			return e.WriteBool(b)
+		},
+		func(d *Decoder) (interface{}, error) { return d.ReadBool() },
+	},
+	"int8": {
+		func(e *Encoder, v interface{}) error {
+			n, err := parseABIInt(v, 8)
+			if err != nil {
+				return err
+			}
+			return e.WriteByte(byte(n))
+		},
+		func(d *Decoder) (interface{}, error) {
+			n, err := d.ReadByte()
+			return int8(n), err
+		},
+	},
+	"uint8": {
+		func(e *Encoder, v interface{}) error {
+			n, err := parseABIUint(v, 8)
+			if err != nil {
+				return err
+			}
+			return e.WriteByte(byte(n))
+		},
+		func(d *Decoder) (interface{}, error) { return d.ReadByte() },
+	},
+	"int16": {
+		func(e *Encoder, v interface{}) error {
+			n, err := parseABIInt(v, 16)
+			if err != nil {
+				return err
+			}
+			return e.WriteUint16(uint16(n))
+		},
+		func(d *Decoder) (interface{}, error) { return d.ReadInt16() },
+	},
+	"uint16": {
+		func(e *Encoder, v interface{}) error {
+			n, err := parseABIUint(v, 16)
+			if err != nil {
+				return err
+			}
+			return e.WriteUint16(uint16(n))
+		},
+		func(d *Decoder) (interface{}, error) { return d.ReadUint16() },
+	},
+	"int32": {
+		func(e *Encoder, v interface{}) error {
+			n, err := parseABIInt(v, 32)
+			if err != nil {
+				return err
+			}
+			return e.WriteUint32(uint32(n))
+		},
+		func(d *Decoder) (interface{}, error) {
+			n, err := d.ReadUint32()
+			return int32(n), err
+		},
+	},
+	"uint32": {
+		func(e *Encoder, v interface{}) error {
+			n, err := parseABIUint(v, 32)
+			if err != nil {
+				return err
+			}
+			return e.WriteUint32(uint32(n))
+		},
+		func(d *Decoder) (interface{}, error) { return d.ReadUint32() },
+	},
+	"int64": {
+		func(e *Encoder, v interface{}) error {
+			n, err := parseABIInt(v, 64)
+			if err != nil {
+				return err
+			}
+			return e.WriteUint64(uint64(n))
+		},
+		func(d *Decoder) (interface{}, error) { return d.readInt64() },
+	},
+	"uint64": {
+		func(e *Encoder, v interface{}) error {
+			n, err := parseABIUint(v, 64)
+			if err != nil {
+				return err
+			}
+			return e.WriteUint64(n)
+		},
+		func(d *Decoder) (interface{}, error) { return d.ReadUint64() },
+	},
+	"int128": {
+		func(e *Encoder, v interface{}) error { return writeABIInt128(e, v, true) },
+		func(d *Decoder) (interface{}, error) { return readABIInt128(d, true) },
+	},
+	"uint128": {
+		func(e *Encoder, v interface{}) error { return writeABIInt128(e, v, false) },
+		func(d *Decoder) (interface{}, error) { return readABIInt128(d, false) },
+	},
+	"varint32": {
+		func(e *Encoder, v interface{}) error {
+			n, err := parseABIInt(v, 32)
+			if err != nil {
+				return err
+			}
+			return e.WriteUVarInt(int(uint32(n<<1) ^ uint32(n>>31)))
+		},
+		func(d *Decoder) (interface{}, error) {
+			n, err := d.ReadUvarint()
+			if err != nil {
+				return nil, err
+			}
+			if n > math.MaxUint32 {
+				return nil, fmt.Errorf("varint32 out of range")
+			}
+			return int32(uint32(n)>>1) ^ -int32(n&1), nil
+		},
+	},
+	"varuint32": {
+		func(e *Encoder, v interface{}) error {
+			n, err := parseABIUint(v, 32)
+			if err != nil {
+				return err
+			}
+			return e.WriteUVarInt(int(n))
+		},
+		func(d *Decoder) (interface{}, error) {
+			n, err := d.ReadUvarint()
+			if err != nil {
+				return nil, err
+			}
+			if n > math.MaxUint32 {
+				return nil, fmt.Errorf("varuint32 out of range")
+			}
+			return uint32(n), nil
+		},
+	},
+	"float32": {
+		func(e *Encoder, v interface{}) error {
+			f, err := parseABIFloat(v, 32)
+			if err != nil {
+				return err
+			}
+			return e.WriteUint32(math.Float32bits(float32(f)))
+		},
+		func(d *Decoder) (interface{}, error) {
+			n, err := d.ReadUint32()
+			return math.Float32frombits(n), err
+		},
+	},
+	"float64": {
+		func(e *Encoder, v interface{}) error {
+			f, err := parseABIFloat(v, 64)
+			if err != nil {
+				return err
+			}
+			return e.WriteUint64(math.Float64bits(f))
+		},
+		func(d *Decoder) (interface{}, error) {
+			n, err := d.ReadUint64()
+			return math.Float64frombits(n), err
+		},
+	},
+	"float128": {
+		func(e *Encoder, v interface{}) error {
+			s, _ := v.(string)
+			return writeABIHex(e, strings.TrimPrefix(s, "0x"), 16)
+		},
+		func(d *Decoder) (interface{}, error) {
+			data, err := d.readBytes(16)
+			return "0x" + hex.EncodeToString(data), err
+		},
+	},
+	"time_point": {
+		func(e *Encoder, v interface{}) error {
+			t, err := parseABITime(v)
+			if err != nil {
+				return err
+			}
+			return e.WriteUint64(uint64(t.UnixNano() / int64(time.Microsecond)))
+		},
+		func(d *Decoder) (interface{}, error) {
+			n, err := d.readInt64()
+			return formatTimePoint(time.Unix(0, n*int64(time.Microsecond))), err
+		},
+	},
+	"time_point_sec": {
+		func(e *Encoder, v interface{}) error {
+			t, err := parseABITime(v)
+			if err != nil {
+				return err
+			}
+			return e.WriteUint32(uint32(t.Unix()))
+		},
+		func(d *Decoder) (interface{}, error) {
+			t, err := d.readJSONTime()
+			return t.Format(JSONTimeFormat), err
+		},
+	},
+	"block_timestamp_type": {
+		func(e *Encoder, v interface{}) error {
+			t, err := parseABITime(v)
+			if err != nil {
+				return err
+			}
+			// Half-second slots since 2000-01-01.
+			return e.WriteUint32(uint32((t.UnixNano()/int64(time.Millisecond) - 946684800000) / 500))
+		},
+		func(d *Decoder) (interface{}, error) {
+			slot, err := d.ReadUint32()
+			t := time.Unix(0, (int64(slot)*500+946684800000)*int64(time.Millisecond))
+			return t.UTC().Format(BlockTimestampFormat + ".000"), err
+		},
+	},
+	"name": {
+		func(e *Encoder, v interface{}) error {
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("expected a name, got %s", jsonKind(v))
+			}
+			n, err := StringToName(s)
+			if err != nil || NameToString(n) != s {
+				return fmt.Errorf("invalid name %q", s)
+			}
+			return e.WriteUint64(n)
+		},
+		func(d *Decoder) (interface{}, error) {
+			n, err := d.ReadUint64()
+			return NameToString(n), err
+		},
+	},
+	"bytes": {
+		func(e *Encoder, v interface{}) error {
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("expected hex bytes, got %s", jsonKind(v))
+			}
+			data, err := hex.DecodeString(s)
+			if err != nil {
+				return err
+			}
+			return e.WriteByteArray(data)
+		},
+		func(d *Decoder) (interface{}, error) {
+			data, err := d.ReadByteArray()
+			return hex.EncodeToString(data), err
+		},
+	},
+	"string": {
+		func(e *Encoder, v interface{}) error {
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("expected a string, got %s", jsonKind(v))
+			}
+			return e.WriteString(s)
+		},
+		func(d *Decoder) (interface{}, error) { return d.ReadString() },
+	},
+	"checksum160": abiChecksum(20),
+	"checksum256": abiChecksum(32),
+	"checksum512": abiChecksum(64),
+	"public_key": {
+		func(e *Encoder, v interface{}) error {
+			s, _ := v.(string)
+			key, err := ecc.NewPublicKey(s)
+			if err != nil {
+				return err
+			}
+			return e.WritePublicKey(key)
+		},
+		func(d *Decoder) (interface{}, error) {
+			key, err := d.ReadPublicKey()
+			if err != nil {
+				return nil, err
+			}
+			return key.String(), nil
+		},
+	},
+	"signature": {
+		func(e *Encoder, v interface{}) error {
+			s, _ := v.(string)
+			sig, err := ecc.NewSignature(s)
+			if err != nil {
+				return err
+			}
+			return e.WriteSignature(sig)
+		},
+		func(d *Decoder) (interface{}, error) {
+			sig, err := d.ReadSignature()
+			if err != nil {
+				return nil, err
+			}
+			return sig.String(), nil
+		},
+	},
+	"symbol": {
+		func(e *Encoder, v interface{}) error {
+			s, _ := v.(string)
+			parts := strings.SplitN(s, ",", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("invalid symbol %q, expected precision,CODE", s)
+			}
+			precision, err := strconv.ParseUint(parts[0], 10, 8)
+			if err != nil {
+				return fmt.Errorf("invalid symbol %q: %w", s, err)
+			}
+			if err := e.WriteByte(byte(precision)); err != nil {
+				return err
+			}
+			return writeABISymbolCode(e, parts[1], 7)
+		},
+		func(d *Decoder) (interface{}, error) {
+			precision, err := d.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			code, err := d.readBytes(7)
+			return fmt.Sprintf("%d,%s", precision, strings.TrimRight(string(code), "\x00")), err
+		},
+	},
+	"symbol_code": {
+		func(e *Encoder, v interface{}) error {
+			s, _ := v.(string)
+			return writeABISymbolCode(e, s, 8)
+		},
+		func(d *Decoder) (interface{}, error) {
+			code, err := d.readBytes(8)
+			return strings.TrimRight(string(code), "\x00"), err
+		},
+	},
+	"asset": {
+		func(e *Encoder, v interface{}) error {
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("expected an asset, got %s", jsonKind(v))
+			}
+			asset, err := NewAsset(s)
+			if err != nil {
+				return err
+			}
+			if err := e.WriteUint64(uint64(asset.Amount)); err != nil {
+				return err
+			}
+			if err := e.WriteByte(asset.Precision); err != nil {
+				return err
+			}
+			return writeABISymbolCode(e, asset.Symbol.Symbol, 7)
+		},
+		func(d *Decoder) (interface{}, error) {
+			amount, err := d.readInt64()
+			if err != nil {
+				return nil, err
+			}
+			precision, err := d.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			code, err := d.readBytes(7)
+			if err != nil {
+				return nil, err
+			}
+			asset := Asset{Amount: amount, Symbol: Symbol{Precision: precision, Symbol: strings.TrimRight(string(code), "\x00")}}
+			return asset.String(), nil
+		},
+	},
+}
+
+func abiChecksum(size int) abiBuiltin {
+	return abiBuiltin{
+		func(e *Encoder, v interface{}) error {
+			s, _ := v.(string)
+			return writeABIHex(e, s, size)
+		},
+		func(d *Decoder) (interface{}, error) {
+			data, err := d.readBytes(size)
+			return hex.EncodeToString(data), err
+		},
+	}
+}
+
+func writeABIHex(e *Encoder, s string, size int) error {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(data) != size {
+		return fmt.Errorf("expected %d bytes, got %d", size, len(data))
+	}
+	return e.toWriter(data)
+}
+
+func writeABISymbolCode(e *Encoder, code string, size int) error {
+	if code == "" || len(code) > 7 {
+		return fmt.Errorf("invalid symbol code %q", code)
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return fmt.Errorf("invalid symbol code %q", code)
+		}
+	}
+
+	out := make([]byte, size)
+	copy(out, code)
+	return e.toWriter(out)
+}
+
+// abiNumber returns the text of a number given as a JSON number or
+// string, nodeos accepting both.
+func abiNumber(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return string(v), nil
+	case string:
+		return v, nil
+	}
+	return "", fmt.Errorf("expected a number, got %s", jsonKind(v))
+}
+
+func parseABIInt(v interface{}, bits int) (int64, error) {
+	s, err := abiNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s, 10, bits)
+}
+
+func parseABIUint(v interface{}, bits int) (uint64, error) {
+	s, err := abiNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(s, 10, bits)
+}
+
+func parseABIFloat(v interface{}, bits int) (float64, error) {
+	s, err := abiNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(s, bits)
+}
+
+// formatTimePoint gives milliseconds like `nodeos`, or microseconds
+// when there are some, so that times round trip.
+func formatTimePoint(t time.Time) string {
+	s := t.UTC().Format(JSONTimeFormat + ".000000")
+	return strings.TrimSuffix(s, "000")
+}
+
+func parseABITime(v interface{}) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("expected a time, got %s", jsonKind(v))
+	}
+	// Fractional seconds are accepted when parsing, even if not in
+	// the layout.
+	return time.Parse(JSONTimeFormat, strings.TrimSuffix(s, "Z"))
+}
+
+var (
+	maxUint128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	maxInt128  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	minInt128  = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	twoTo128   = new(big.Int).Lsh(big.NewInt(1), 128)
+)
+
+// writeABIInt128 writes a 128 bits integer, given in decimal or in
+// hexadecimal with a `0x` prefix, as 16 little endian bytes.
+func writeABIInt128(e *Encoder, v interface{}, signed bool) error {
+	s, err := abiNumber(v)
+	if err != nil {
+		return err
+	}
+
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return fmt.Errorf("invalid 128 bits integer %q", s)
+	}
+	if signed && (n.Cmp(minInt128) < 0 || n.Cmp(maxInt128) > 0) || !signed && (n.Sign() < 0 || n.Cmp(maxUint128) > 0) {
+		return fmt.Errorf("128 bits integer %s out of range", s)
+	}
+	if n.Sign() < 0 {
+		n.Add(n, twoTo128)
+	}
+
+	buf := make([]byte, 16)
+	n.FillBytes(buf)
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return e.toWriter(buf)
+}
+
+func readABIInt128(d *Decoder, signed bool) (interface{}, error) {
+	data, err := d.readBytes(16)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 16)
+	for i := range data {
+		buf[15-i] = data[i]
+	}
+	n := new(big.Int).SetBytes(buf)
+	if signed && n.Cmp(maxInt128) > 0 {
+		n.Sub(n, twoTo128)
+	}
+	return n.String(), nil
+}
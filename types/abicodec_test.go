@@ -0,0 +1,191 @@
+package types_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/Akagi201/eosgo/token"
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testABI = `{
+	"version": "eosio::abi/1.1",
+	"types": [
+		{"new_type_name": "account_name", "type": "name"},
+		{"new_type_name": "recipients", "type": "account_name[]"}
+	],
+	"structs": [
+		{"name": "transfer", "base": "", "fields": [
+			{"name": "from", "type": "account_name"},
+			{"name": "to", "type": "account_name"},
+			{"name": "quantity", "type": "asset"},
+			{"name": "memo", "type": "string"}
+		]},
+		{"name": "header", "base": "", "fields": [
+			{"name": "id", "type": "uint64"},
+			{"name": "when", "type": "time_point_sec"}
+		]},
+		{"name": "payout", "base": "header", "fields": [
+			{"name": "to", "type": "recipients"},
+			{"name": "total", "type": "extended_asset"},
+			{"name": "ratio", "type": "float64"},
+			{"name": "delta", "type": "varint32"},
+			{"name": "big", "type": "int128"},
+			{"name": "key", "type": "public_key?"},
+			{"name": "note", "type": "string?"},
+			{"name": "target", "type": "target"},
+			{"name": "hash", "type": "checksum256$"},
+			{"name": "tags", "type": "symbol_code[]$"}
+		]}
+	],
+	"variants": [
+		{"name": "target", "types": ["name", "uint32"]}
+	],
+	"actions": [
+		{"name": "transfer", "type": "transfer", "ricardian_contract": ""},
+		{"name": "payout", "type": "payout", "ricardian_contract": ""}
+	],
+	"tables": [
+		{"name": "payouts", "index_type": "i64", "key_names": [], "key_types": [], "type": "payout"}
+	]
+}`
+
+func loadTestABI(t *testing.T) *types.ABI {
+	var abi types.ABI
+	require.NoError(t, json.Unmarshal([]byte(testABI), &abi))
+	return &abi
+}
+
+func TestABI_EncodeAction(t *testing.T) {
+	abi := loadTestABI(t)
+
+	bin, err := abi.EncodeAction("transfer", map[string]interface{}{
+		"from": "alice", "to": "bob", "quantity": "1.0000 EOS", "memo": "hi",
+	})
+	require.NoError(t, err)
+
+	expected, err := types.MarshalBinary(token.Transfer{From: "alice", To: "bob", Quantity: types.NewEOSAsset(10000), Memo: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(bin))
+
+	data, err := abi.DecodeAction("transfer", bin)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"from": "alice", "to": "bob", "quantity": "1.0000 EOS", "memo": "hi"}, data)
+
+	actionData, err := abi.NewActionData("transfer", token.Transfer{From: "alice", To: "bob", Quantity: types.NewEOSAsset(10000), Memo: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, bin, []byte(actionData.HexData))
+}
+
+func TestABI_RoundTrip(t *testing.T) {
+	abi := loadTestABI(t)
+
+	payout := `{
+		"id": "18446744073709551615",
+		"when": "2018-06-15T08:03:32",
+		"to": ["alice", "bob"],
+		"total": {"quantity": "12.5000 EOS", "contract": "eosio.token"},
+		"ratio": 0.25,
+		"delta": -3,
+		"big": "-170141183460469231731687303715884105728",
+		"key": "EOS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV",
+		"note": null,
+		"target": ["uint32", 7],
+		"hash": "399872d366dae737d1e483f5544a4b8933db8be78717edc77eeb1cbb9c22c50b"
+	}`
+	bin, err := abi.EncodeAction("payout", json.RawMessage(payout))
+	require.NoError(t, err)
+
+	row, err := abi.DecodeTableRow("payouts", bin)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(18446744073709551615), row["id"])
+	assert.Equal(t, "2018-06-15T08:03:32", row["when"])
+	assert.Equal(t, []interface{}{"alice", "bob"}, row["to"])
+	assert.Equal(t, map[string]interface{}{"quantity": "12.5000 EOS", "contract": "eosio.token"}, row["total"])
+	assert.Equal(t, 0.25, row["ratio"])
+	assert.Equal(t, int32(-3), row["delta"])
+	assert.Equal(t, "-170141183460469231731687303715884105728", row["big"])
+	assert.Equal(t, "EOS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV", row["key"])
+	assert.Nil(t, row["note"])
+	assert.Equal(t, []interface{}{"uint32", uint32(7)}, row["target"])
+	assert.Equal(t, "399872d366dae737d1e483f5544a4b8933db8be78717edc77eeb1cbb9c22c50b", row["hash"])
+	assert.NotContains(t, row, "tags", "missing binary extensions are left out")
+
+	again, err := abi.EncodeAction("payout", row)
+	require.NoError(t, err)
+	assert.Equal(t, bin, again)
+}
+
+func TestABI_EncodeErrors(t *testing.T) {
+	abi := loadTestABI(t)
+
+	tests := []struct {
+		action string
+		data   string
+		err    string
+	}{
+		{"transfer", `{"from": "alice", "to": "bob", "quantity": "1.0000 EOS"}`, `missing field "memo"`},
+		{"transfer", `{"from": "Alice", "to": "bob", "quantity": "1.0000 EOS", "memo": ""}`, `transfer.from: invalid name "Alice"`},
+		{"transfer", `{"from": "alice", "to": "bob", "quantity": 1, "memo": ""}`, `transfer.quantity: expected an asset, got number 1`},
+		{"payout", `{"id": 1, "when": "2018-06-15T08:03:32", "to": [], "total": {"quantity": "1 EOS", "contract": "eosio"}, "ratio": 1, "delta": 1, "big": 1, "target": ["int8", 1]}`, `type "int8" is not part of variant target`},
+		{"payout", `{"id": 1, "when": "2018-06-15T08:03:32", "to": [], "total": {"quantity": "1 EOS", "contract": "eosio"}, "ratio": 1, "delta": 1, "big": 1, "target": ["name", "eosio"], "tags": []}`, `binary extension present after missing ones`},
+		{"nope", `{}`, `action "nope" not found in ABI`},
+	}
+
+	for _, test := range tests {
+		_, err := abi.EncodeAction(types.ActionName(test.action), json.RawMessage(test.data))
+		require.Error(t, err, test.data)
+		assert.Contains(t, err.Error(), test.err)
+	}
+
+	_, err := abi.DecodeAction("transfer", []byte{1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestABI_Recursive(t *testing.T) {
+	abi := &types.ABI{
+		Types:   []types.ABIType{{NewTypeName: "a", Type: "b"}, {NewTypeName: "b", Type: "a"}},
+		Structs: []types.StructDef{{Name: "node", Fields: []types.FieldDef{{Name: "next", Type: "node?"}}}},
+	}
+
+	_, err := abi.EncodeType("a", 1)
+	assert.Error(t, err)
+
+	_, err = abi.EncodeType("node", map[string]interface{}{"next": map[string]interface{}{"next": nil}})
+	require.NoError(t, err)
+
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = 1
+	}
+	_, err = abi.DecodeType("node", data)
+	assert.Error(t, err)
+}
+
+func TestABI_DecodeEdgeCases(t *testing.T) {
+	abi := &types.ABI{
+		Structs: []types.StructDef{{Name: "empty"}, {Name: "holder", Fields: []types.FieldDef{{Name: "items", Type: "empty[]"}}}},
+	}
+
+	for _, when := range []string{"2018-06-15T08:03:32.123456", "2018-06-15T08:03:32.500", "2018-06-15T08:03:32.000"} {
+		bin, err := abi.EncodeType("time_point", when)
+		require.NoError(t, err)
+		decoded, err := abi.DecodeType("time_point", bin)
+		require.NoError(t, err)
+		assert.Equal(t, when, decoded)
+	}
+
+	// Empty structs take no bytes, so there can be more items than
+	// bytes left.
+	decoded, err := abi.DecodeType("holder", []byte{3})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"items": []interface{}{
+		map[string]interface{}{}, map[string]interface{}{}, map[string]interface{}{},
+	}}, decoded)
+
+	_, err = abi.DecodeType("string[]", []byte{3, 0})
+	assert.Error(t, err)
+}
@@ -0,0 +1,282 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ABIProblem is an issue found by ABI.Validate.  `Path` locates it in
+// the JSON form of the ABI, like `structs[2].fields[0].type`.
+type ABIProblem struct {
+	Path    string
+	Message string
+}
+
+func (p ABIProblem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+// ABIValidationError lists all the problems of an ABI.
+type ABIValidationError struct {
+	Problems []ABIProblem
+}
+
+func (e *ABIValidationError) Error() string {
+	var problems []string
+	for _, problem := range e.Problems {
+		problems = append(problems, problem.String())
+	}
+	return fmt.Sprintf("invalid ABI: %s", strings.Join(problems, "; "))
+}
+
+// abiIndexTypes are the `index_type` of tables known to nodeos.
+var abiIndexTypes = map[string]bool{
+	"i64":       true,
+	"i128":      true,
+	"i256":      true,
+	"float64":   true,
+	"float128":  true,
+	"sha256":    true,
+	"ripemd160": true,
+}
+
+// Validate checks the ABI before it's deployed, like nodeos does on
+// `setabi`, and a bit more.  It reports the types that can't be
+// resolved, circular aliases and struct bases, type names already
+// taken by another type or a built-in one (`extended_asset` included),
+// duplicate action and table names, actions and tables of types which
+// are not structs,
+// unknown table index types, misplaced binary extensions, and action
+// and table names which are not valid account names.  It returns nil
+// or an *ABIValidationError.
+func (a *ABI) Validate() error {
+	v := &abiValidator{abi: a, codec: newABICodec(a)}
+	v.validate()
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ABIValidationError{Problems: v.problems}
+}
+
+type abiValidator struct {
+	abi      *ABI
+	codec    *abiCodec
+	problems []ABIProblem
+}
+
+func (v *abiValidator) report(path, format string, args ...interface{}) {
+	v.problems = append(v.problems, ABIProblem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *abiValidator) validate() {
+	a := v.abi
+	if !strings.HasPrefix(a.Version, "eosio::abi/1.") {
+		v.report("version", "unsupported version %q", a.Version)
+	}
+
+	// Type names are shared by aliases, structs and variants.
+	defined := map[string]string{}
+	define := func(name, path string) {
+		if _, ok := abiBuiltins[name]; ok || name == extendedAssetDef.Name {
+			v.report(path, "%q redefines a built-in type", name)
+		} else if other, ok := defined[name]; ok {
+			v.report(path, "%q is already defined at %s", name, other)
+		} else {
+			defined[name] = path
+		}
+	}
+
+	for idx, typedef := range a.Types {
+		path := fmt.Sprintf("types[%d]", idx)
+		define(typedef.NewTypeName, path+".new_type_name")
+		if v.aliasLoops(typedef.NewTypeName) {
+			v.report(path+".type", "circular alias %q", typedef.NewTypeName)
+		} else {
+			v.checkType(path+".type", typedef.Type)
+		}
+	}
+
+	for idx, def := range a.Structs {
+		path := fmt.Sprintf("structs[%d]", idx)
+		define(def.Name, path+".name")
+		v.checkStruct(path, def)
+	}
+
+	for idx, def := range a.Variants {
+		path := fmt.Sprintf("variants[%d]", idx)
+		define(def.Name, path+".name")
+		for typeIdx, typeName := range def.Types {
+			v.checkType(fmt.Sprintf("%s.types[%d]", path, typeIdx), typeName)
+		}
+	}
+
+	actions := map[ActionName]string{}
+	for idx, def := range a.Actions {
+		path := fmt.Sprintf("actions[%d]", idx)
+		v.checkName(path+".name", string(def.Name))
+		if other, ok := actions[def.Name]; ok {
+			v.report(path+".name", "action %q is already defined at %s", def.Name, other)
+		} else {
+			actions[def.Name] = path
+		}
+		v.checkStructType(path+".type", def.Type)
+	}
+
+	tables := map[TableName]string{}
+	for idx, def := range a.Tables {
+		path := fmt.Sprintf("tables[%d]", idx)
+		v.checkName(path+".name", string(def.Name))
+		if other, ok := tables[def.Name]; ok {
+			v.report(path+".name", "table %q is already defined at %s", def.Name, other)
+		} else {
+			tables[def.Name] = path
+		}
+		if !abiIndexTypes[def.IndexType] {
+			v.report(path+".index_type", "unknown index type %q", def.IndexType)
+		}
+		if len(def.KeyNames) != len(def.KeyTypes) {
+			v.report(path, "%d key_names for %d key_types", len(def.KeyNames), len(def.KeyTypes))
+		}
+		for keyIdx, keyType := range def.KeyTypes {
+			v.checkType(fmt.Sprintf("%s.key_types[%d]", path, keyIdx), keyType)
+		}
+		v.checkStructType(path+".type", def.Type)
+	}
+
+	codes := map[uint64]string{}
+	for idx, msg := range a.ErrorMessages {
+		path := fmt.Sprintf("error_messages[%d].error_code", idx)
+		if other, ok := codes[msg.Code]; ok {
+			v.report(path, "error code %d is already defined at %s", msg.Code, other)
+		} else {
+			codes[msg.Code] = path
+		}
+	}
+}
+
+func (v *abiValidator) checkStruct(path string, def StructDef) {
+	if def.Base != "" {
+		if v.baseLoops(def) {
+			v.report(path+".base", "circular base chain from struct %q", def.Name)
+		} else {
+			v.checkStructType(path+".base", def.Base)
+		}
+	}
+
+	fields := map[string]bool{}
+	extension := false
+	for idx, field := range def.Fields {
+		fieldPath := fmt.Sprintf("%s.fields[%d]", path, idx)
+		if fields[field.Name] {
+			v.report(fieldPath+".name", "duplicate field %q in struct %q", field.Name, def.Name)
+		}
+		fields[field.Name] = true
+
+		if strings.HasSuffix(field.Type, "$") {
+			extension = true
+		} else if extension {
+			v.report(fieldPath+".type", "field %q follows a binary extension without being one", field.Name)
+		}
+		v.checkType(fieldPath+".type", strings.TrimSuffix(field.Type, "$"))
+	}
+}
+
+// checkType reports types that don't resolve to a built-in type, a
+// struct or a variant.
+func (v *abiValidator) checkType(path, typeName string) {
+	resolved := typeName
+	for depth := 0; depth <= maxABIDepth; depth++ {
+		switch {
+		case strings.HasSuffix(resolved, "?"):
+			resolved = strings.TrimSuffix(resolved, "?")
+			continue
+		case strings.HasSuffix(resolved, "[]"):
+			resolved = strings.TrimSuffix(resolved, "[]")
+			continue
+		case strings.HasSuffix(resolved, "$"):
+			v.report(path, "binary extension %q outside of a struct field", typeName)
+			return
+		}
+
+		if _, ok := abiBuiltins[resolved]; ok {
+			return
+		}
+		if _, ok := v.codec.structs[resolved]; ok {
+			return
+		}
+		if _, ok := v.codec.variants[resolved]; ok {
+			return
+		}
+		target, ok := v.codec.typedefs[resolved]
+		if !ok {
+			if resolved == typeName {
+				v.report(path, "unknown type %q", typeName)
+			} else {
+				v.report(path, "unknown type %q in %q", resolved, typeName)
+			}
+			return
+		}
+		resolved = target
+	}
+	v.report(path, "type %q doesn't resolve, circular alias", typeName)
+}
+
+// checkStructType reports types which are not structs, for actions,
+// tables and bases.
+func (v *abiValidator) checkStructType(path, typeName string) {
+	resolved, err := v.codec.resolve(typeName)
+	if err != nil {
+		v.report(path, "type %q doesn't resolve, circular alias", typeName)
+		return
+	}
+	if _, ok := v.codec.structs[resolved]; !ok {
+		if _, known := abiBuiltins[resolved]; known || v.codec.variants[resolved] != nil {
+			v.report(path, "type %q is not a struct", typeName)
+		} else {
+			v.report(path, "unknown struct %q", typeName)
+		}
+	}
+}
+
+func (v *abiValidator) checkName(path, name string) {
+	n, _ := StringToName(name)
+	if name == "" || NameToString(n) != name {
+		v.report(path, "invalid name %q", name)
+	}
+}
+
+// aliasLoops tells whether the alias `name` leads back to itself.
+func (v *abiValidator) aliasLoops(name string) bool {
+	current := name
+	for depth := 0; depth <= len(v.codec.typedefs); depth++ {
+		target, ok := v.codec.typedefs[current]
+		if !ok {
+			return false
+		}
+		if target == name {
+			return true
+		}
+		current = target
+	}
+	return false
+}
+
+// baseLoops tells whether the bases of `def` lead back to it.
+func (v *abiValidator) baseLoops(def StructDef) bool {
+	current := def
+	for depth := 0; depth <= len(v.codec.structs); depth++ {
+		baseName, err := v.codec.resolve(current.Base)
+		if err != nil || current.Base == "" {
+			return false
+		}
+		base, ok := v.codec.structs[baseName]
+		if !ok {
+			return false
+		}
+		if base.Name == def.Name {
+			return true
+		}
+		current = *base
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestABI_Validate(t *testing.T) {
+	assert.NoError(t, loadTestABI(t).Validate())
+	assert.NoError(t, binaryABI.Validate())
+
+	abi := &types.ABI{
+		Version: "eosio::abi/1.1",
+		Types: []types.ABIType{
+			{NewTypeName: "a", Type: "b"},
+			{NewTypeName: "b", Type: "a"},
+			{NewTypeName: "ids", Type: "uint65[]"},
+			{NewTypeName: "name", Type: "string"},
+		},
+		Structs: []types.StructDef{
+			{Name: "transfer", Fields: []types.FieldDef{
+				{Name: "from", Type: "name"},
+				{Name: "from", Type: "nmae"},
+				{Name: "extra", Type: "string$"},
+				{Name: "memo", Type: "string"},
+			}},
+			{Name: "left", Base: "right"},
+			{Name: "right", Base: "left"},
+			{Name: "transfer"},
+			{Name: "extended_asset", Fields: []types.FieldDef{{Name: "quantity", Type: "asset"}}},
+		},
+		Variants: []types.VariantDef{{Name: "v", Types: []string{"name", "missing?"}}},
+		Actions: []types.ActionDef{
+			{Name: "transfer", Type: "transfer"},
+			{Name: "transfer", Type: "transfer"},
+			{Name: "Pay", Type: "pay"},
+			{Name: "choose", Type: "v"},
+		},
+		Tables: []types.TableDef{
+			{Name: "accounts", IndexType: "i65", KeyNames: []string{"id"}, Type: "transfer"},
+			{Name: "toolongtablename", IndexType: "i64", Type: "string"},
+		},
+		ErrorMessages: []types.ABIErrorMessage{{Code: 1}, {Code: 1}},
+	}
+
+	err := abi.Validate()
+	require.Error(t, err)
+	validationErr, ok := err.(*types.ABIValidationError)
+	require.True(t, ok, "got %T", err)
+
+	problems := map[string]string{}
+	for _, problem := range validationErr.Problems {
+		problems[problem.Path] = problem.Message
+	}
+	assert.Equal(t, map[string]string{
+		"types[0].type":                `circular alias "a"`,
+		"types[1].type":                `circular alias "b"`,
+		"types[2].type":                `unknown type "uint65" in "uint65[]"`,
+		"types[3].new_type_name":       `"name" redefines a built-in type`,
+		"structs[0].fields[1].name":    `duplicate field "from" in struct "transfer"`,
+		"structs[0].fields[1].type":    `unknown type "nmae"`,
+		"structs[0].fields[3].type":    `field "memo" follows a binary extension without being one`,
+		"structs[1].base":              `circular base chain from struct "left"`,
+		"structs[2].base":              `circular base chain from struct "right"`,
+		"structs[3].name":              `"transfer" is already defined at structs[0].name`,
+		"structs[4].name":              `"extended_asset" redefines a built-in type`,
+		"variants[0].types[1]":         `unknown type "missing" in "missing?"`,
+		"actions[1].name":              `action "transfer" is already defined at actions[0]`,
+		"actions[2].name":              `invalid name "Pay"`,
+		"actions[2].type":              `unknown struct "pay"`,
+		"actions[3].type":              `type "v" is not a struct`,
+		"tables[0].index_type":         `unknown index type "i65"`,
+		"tables[0]":                    `1 key_names for 0 key_types`,
+		"tables[1].name":               `invalid name "toolongtablename"`,
+		"tables[1].type":               `type "string" is not a struct`,
+		"error_messages[1].error_code": `error code 1 is already defined at error_messages[0].error_code`,
+	}, problems)
+	assert.Len(t, validationErr.Problems, len(problems))
+	assert.Contains(t, err.Error(), `actions[2].name: invalid name "Pay"`)
+
+	assert.Error(t, (&types.ABI{Version: "eosio::abi/2.0"}).Validate())
+}
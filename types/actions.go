@@ -19,12 +19,44 @@ type SetCode struct {
 	Code      HexBytes    `json:"bytes"`
 }
 
-// SetABI represents the hard-coded `setabi` action.
+// SetABI represents the hard-coded `setabi` action.  The ABI is
+// packed as bytes, see ABI.MarshalBinary.
 type SetABI struct {
 	Account AccountName `json:"account"`
 	ABI     ABI         `json:"abi"`
 }
 
+// NewSetCode returns the `setcode` action deploying the WebAssembly
+// `code` on `account`.
+func NewSetCode(account AccountName, code []byte) *Action {
+	return &Action{
+		Account: AN("eosio"),
+		Name:    ActN("setcode"),
+		Authorization: []PermissionLevel{
+			{Actor: account, Permission: PN("active")},
+		},
+		ActionData: NewActionData(SetCode{
+			Account: account,
+			Code:    code,
+		}),
+	}
+}
+
+// NewSetABI returns the `setabi` action deploying `abi` on `account`.
+func NewSetABI(account AccountName, abi ABI) *Action {
+	return &Action{
+		Account: AN("eosio"),
+		Name:    ActN("setabi"),
+		Authorization: []PermissionLevel{
+			{Actor: account, Permission: PN("active")},
+		},
+		ActionData: NewActionData(SetABI{
+			Account: account,
+			ABI:     abi,
+		}),
+	}
+}
+
 // Action
 type Action struct {
 	Account       AccountName       `json:"account"`
@@ -2,15 +2,15 @@ package types
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"net/http"
-	"net/http/httputil"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,61 +26,104 @@ type API struct {
 	DefaultMaxCPUUsageMS    uint8
 	DefaultMaxNetUsageWords uint32 // in 8-bytes words
 
+	// InfoCache decides how `get_info` answers are reused to fill
+	// transactions.  Defaults to a one second cache.
+	InfoCache *InfoCachePolicy
+
 	lastGetInfo      *InfoResp
 	lastGetInfoStamp time.Time
 	lastGetInfoLock  sync.Mutex
 
 	customGetRequiredKeys func(tx *Transaction) ([]ecc.PublicKey, error)
+
+	// Pool, when set (see NewPool), spreads calls over several
+	// endpoints instead of `BaseURL`.
+	Pool *Pool
+
+	// Retry, when set, retries failed calls. See RetryPolicy.
+	Retry *RetryPolicy
+
+	// Logger receives the `Debug` output. Defaults to the package
+	// Logger, see SetLogger.
+	Logger Logger
+
+	// Interceptors observe every HTTP call made. See Use.
+	Interceptors []Interceptor
 }
 
 func New(baseURL string) *API {
 	api := &API{
-		HttpClient: &http.Client{
-			Transport: &http.Transport{
-				Proxy: http.ProxyFromEnvironment,
-				DialContext: (&net.Dialer{
-					Timeout:   30 * time.Second,
-					KeepAlive: 30 * time.Second,
-					DualStack: true,
-				}).DialContext,
-				MaxIdleConns:          100,
-				IdleConnTimeout:       90 * time.Second,
-				TLSHandshakeTimeout:   10 * time.Second,
-				ExpectContinueTimeout: 1 * time.Second,
-				DisableKeepAlives:     true, // default behavior, because of `nodeos`'s lack of support for Keep alives.
-			},
-		},
-		BaseURL:  baseURL,
-		Compress: CompressionZlib,
+		HttpClient: newHTTPClient(),
+		BaseURL:    baseURL,
+		Compress:   CompressionZlib,
 	}
 
 	return api
 }
 
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+				DualStack: true,
+			}).DialContext,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+			DisableKeepAlives:     true, // default behavior, because of `nodeos`'s lack of support for Keep alives.
+		},
+	}
+}
+
 // FixKeepAlives tests the remote server for keepalive support (the
 // main `nodeos` software doesn't in the version from March 22nd
 // 2018).  Some endpoints front their node with a keep-alive
 // supporting web server.  Adjust the `KeepAlive` support of the
 // client accordingly.
+//
+// With a Pool, each endpoint is tested and adjusted on its own, and
+// this returns true if any of them got its keep alives disabled.
 func (api *API) FixKeepAlives() bool {
+	if api.Pool == nil {
+		return api.fixKeepAlives(api.BaseURL, api.HttpClient)
+	}
+
+	fixed := false
+	for _, ep := range api.Pool.Endpoints {
+		if api.fixKeepAlives(ep.BaseURL, ep.HttpClient) {
+			fixed = true
+		}
+	}
+	return fixed
+}
+
+func (api *API) fixKeepAlives(baseURL string, client *http.Client) bool {
+	ctx := context.Background()
+
 	// Yeah, to provoke a keep alive, you need to query twice.
 	for i := 0; i < 5; i++ {
-		_, err := api.GetInfo()
+		var info *InfoResp
+		err := api.doRequest(ctx, baseURL, client, "chain", "get_info", nil, &info)
 		if api.Debug {
-			log.Println("err", err)
+			api.logger().Debug("fix keep alives", "url", baseURL, "call", "get_info", "err", err)
 		}
-		if err == io.EOF {
-			if tr, ok := api.HttpClient.Transport.(*http.Transport); ok {
+		if errors.Is(err, io.EOF) {
+			if tr, ok := client.Transport.(*http.Transport); ok {
 				tr.DisableKeepAlives = true
 				return true
 			}
 		}
-		_, err = api.GetNetConnections()
+		var conns []*NetConnectionsResp
+		err = api.doRequest(ctx, baseURL, client, "net", "connections", nil, &conns)
 		if api.Debug {
-			log.Println("err", err)
+			api.logger().Debug("fix keep alives", "url", baseURL, "call", "connections", "err", err)
 		}
-		if err == io.EOF {
-			if tr, ok := api.HttpClient.Transport.(*http.Transport); ok {
+		if errors.Is(err, io.EOF) {
+			if tr, ok := client.Transport.(*http.Transport); ok {
 				tr.DisableKeepAlives = true
 				return true
 			}
@@ -89,8 +132,24 @@ func (api *API) FixKeepAlives() bool {
 	return false
 }
 
+// EnableKeepAlives turns keep alives back on, on every endpoint when
+// using a Pool.
 func (api *API) EnableKeepAlives() bool {
-	if tr, ok := api.HttpClient.Transport.(*http.Transport); ok {
+	if api.Pool == nil {
+		return enableKeepAlives(api.HttpClient)
+	}
+
+	enabled := false
+	for _, ep := range api.Pool.Endpoints {
+		if enableKeepAlives(ep.HttpClient) {
+			enabled = true
+		}
+	}
+	return enabled
+}
+
+func enableKeepAlives(client *http.Client) bool {
+	if tr, ok := client.Transport.(*http.Transport); ok {
 		tr.DisableKeepAlives = false
 		return true
 	}
@@ -108,46 +167,333 @@ func (api *API) SetSigner(s Signer) {
 // ProducerPause will pause block production on a nodeos with
 // `producer_api` plugin loaded.
 func (api *API) ProducerPause() error {
-	return api.call("producer", "pause", nil, nil)
+	return api.ProducerPauseContext(context.Background())
+}
+
+func (api *API) ProducerPauseContext(ctx context.Context) error {
+	return api.call(ctx, "producer", "pause", nil, nil)
 }
 
 // ProducerResume will resume block production on a nodeos with
 // `producer_api` plugin loaded. Obviously, this needs to be a
 // producing node on the producers schedule for it to do anything.
 func (api *API) ProducerResume() error {
-	return api.call("producer", "resume", nil, nil)
+	return api.ProducerResumeContext(context.Background())
+}
+
+func (api *API) ProducerResumeContext(ctx context.Context) error {
+	return api.call(ctx, "producer", "resume", nil, nil)
 }
 
 // IsProducerPaused queries the blockchain for the pause statement of
 // block production.
 func (api *API) IsProducerPaused() (out bool, err error) {
-	err = api.call("producer", "paused", nil, &out)
+	return api.IsProducerPausedContext(context.Background())
+}
+
+func (api *API) IsProducerPausedContext(ctx context.Context) (out bool, err error) {
+	err = api.call(ctx, "producer", "paused", nil, &out)
+	return
+}
+
+// GetProducerRuntimeOptions returns the options of the producer
+// plugin that can be changed without restarting `nodeos`.
+func (api *API) GetProducerRuntimeOptions() (out *ProducerRuntimeOptions, err error) {
+	return api.GetProducerRuntimeOptionsContext(context.Background())
+}
+
+func (api *API) GetProducerRuntimeOptionsContext(ctx context.Context) (out *ProducerRuntimeOptions, err error) {
+	err = api.call(ctx, "producer", "get_runtime_options", nil, &out)
+	return
+}
+
+// UpdateProducerRuntimeOptions changes the options set in `opts`,
+// leaving the nil ones untouched.
+func (api *API) UpdateProducerRuntimeOptions(opts ProducerRuntimeOptions) error {
+	return api.UpdateProducerRuntimeOptionsContext(context.Background(), opts)
+}
+
+func (api *API) UpdateProducerRuntimeOptionsContext(ctx context.Context, opts ProducerRuntimeOptions) error {
+	return api.call(ctx, "producer", "update_runtime_options", opts, nil)
+}
+
+// GetGreylist returns the accounts whose resources are limited to
+// their own stake, without the elastic virtual limits.
+func (api *API) GetGreylist() (out []AccountName, err error) {
+	return api.GetGreylistContext(context.Background())
+}
+
+func (api *API) GetGreylistContext(ctx context.Context) (out []AccountName, err error) {
+	var resp ProducerGreylist
+	err = api.call(ctx, "producer", "get_greylist", nil, &resp)
+	return resp.Accounts, err
+}
+
+func (api *API) AddGreylistAccounts(accounts ...AccountName) error {
+	return api.AddGreylistAccountsContext(context.Background(), accounts...)
+}
+
+func (api *API) AddGreylistAccountsContext(ctx context.Context, accounts ...AccountName) error {
+	return api.call(ctx, "producer", "add_greylist_accounts", ProducerGreylist{Accounts: accounts}, nil)
+}
+
+func (api *API) RemoveGreylistAccounts(accounts ...AccountName) error {
+	return api.RemoveGreylistAccountsContext(context.Background(), accounts...)
+}
+
+func (api *API) RemoveGreylistAccountsContext(ctx context.Context, accounts ...AccountName) error {
+	return api.call(ctx, "producer", "remove_greylist_accounts", ProducerGreylist{Accounts: accounts}, nil)
+}
+
+// GetWhitelistBlacklist returns the actors, contracts, actions and
+// keys filtered by the node.
+func (api *API) GetWhitelistBlacklist() (out *WhitelistBlacklist, err error) {
+	return api.GetWhitelistBlacklistContext(context.Background())
+}
+
+func (api *API) GetWhitelistBlacklistContext(ctx context.Context) (out *WhitelistBlacklist, err error) {
+	err = api.call(ctx, "producer", "get_whitelist_blacklist", nil, &out)
+	return
+}
+
+// SetWhitelistBlacklist replaces the lists set in `lists`, leaving the
+// nil ones untouched.  Set an empty list to clear one.
+func (api *API) SetWhitelistBlacklist(lists WhitelistBlacklist) error {
+	return api.SetWhitelistBlacklistContext(context.Background(), lists)
+}
+
+func (api *API) SetWhitelistBlacklistContext(ctx context.Context, lists WhitelistBlacklist) error {
+	return api.call(ctx, "producer", "set_whitelist_blacklist", lists, nil)
+}
+
+// CreateSnapshot writes a snapshot of the chain state at the head
+// block, in the snapshots directory of the node.
+func (api *API) CreateSnapshot() (out *SnapshotResp, err error) {
+	return api.CreateSnapshotContext(context.Background())
+}
+
+func (api *API) CreateSnapshotContext(ctx context.Context) (out *SnapshotResp, err error) {
+	err = api.call(ctx, "producer", "create_snapshot", nil, &out)
+	return
+}
+
+// GetIntegrityHash returns a hash of the chain state at the head
+// block, to compare nodes with.
+func (api *API) GetIntegrityHash() (out *IntegrityHashResp, err error) {
+	return api.GetIntegrityHashContext(context.Background())
+}
+
+func (api *API) GetIntegrityHashContext(ctx context.Context) (out *IntegrityHashResp, err error) {
+	err = api.call(ctx, "producer", "get_integrity_hash", nil, &out)
+	return
+}
+
+// GetScheduledProtocolFeatureActivations returns the digests of the
+// protocol features the node will activate in its next block.
+func (api *API) GetScheduledProtocolFeatureActivations() (out []SHA256Bytes, err error) {
+	return api.GetScheduledProtocolFeatureActivationsContext(context.Background())
+}
+
+func (api *API) GetScheduledProtocolFeatureActivationsContext(ctx context.Context) (out []SHA256Bytes, err error) {
+	var resp ProtocolFeatureActivations
+	err = api.call(ctx, "producer", "get_scheduled_protocol_feature_activations", nil, &resp)
+	return resp.ProtocolFeaturesToActivate, err
+}
+
+// ScheduleProtocolFeatureActivations makes the node activate the
+// protocol features of `digests` in the next block it produces.
+func (api *API) ScheduleProtocolFeatureActivations(digests ...SHA256Bytes) error {
+	return api.ScheduleProtocolFeatureActivationsContext(context.Background(), digests...)
+}
+
+func (api *API) ScheduleProtocolFeatureActivationsContext(ctx context.Context, digests ...SHA256Bytes) error {
+	return api.call(ctx, "producer", "schedule_protocol_feature_activations", ProtocolFeatureActivations{ProtocolFeaturesToActivate: digests}, nil)
+}
+
+// GetSupportedProtocolFeatures lists the protocol features known to
+// the node.
+func (api *API) GetSupportedProtocolFeatures(params GetSupportedProtocolFeaturesRequest) (out []ProtocolFeature, err error) {
+	return api.GetSupportedProtocolFeaturesContext(context.Background(), params)
+}
+
+func (api *API) GetSupportedProtocolFeaturesContext(ctx context.Context, params GetSupportedProtocolFeaturesRequest) (out []ProtocolFeature, err error) {
+	err = api.call(ctx, "producer", "get_supported_protocol_features", params, &out)
 	return
 }
 
 func (api *API) GetAccount(name AccountName) (out *AccountResp, err error) {
-	err = api.call("chain", "get_account", M{"account_name": name}, &out)
+	return api.GetAccountContext(context.Background(), name)
+}
+
+func (api *API) GetAccountContext(ctx context.Context, name AccountName) (out *AccountResp, err error) {
+	err = api.call(ctx, "chain", "get_account", M{"account_name": name}, &out)
 	return
 }
 
 func (api *API) GetCode(account AccountName) (out *GetCodeResp, err error) {
-	err = api.call("chain", "get_code", M{"account_name": account, "code_as_wasm": true}, &out)
+	return api.GetCodeContext(context.Background(), account)
+}
+
+func (api *API) GetCodeContext(ctx context.Context, account AccountName) (out *GetCodeResp, err error) {
+	err = api.call(ctx, "chain", "get_code", M{"account_name": account, "code_as_wasm": true}, &out)
 	return
 }
 
 func (api *API) GetABI(account AccountName) (out *GetABIResp, err error) {
-	err = api.call("chain", "get_abi", M{"account_name": account}, &out)
+	return api.GetABIContext(context.Background(), account)
+}
+
+func (api *API) GetABIContext(ctx context.Context, account AccountName) (out *GetABIResp, err error) {
+	err = api.call(ctx, "chain", "get_abi", M{"account_name": account}, &out)
+	return
+}
+
+// GetRawABI returns the ABI of `account` as it's stored on chain,
+// packed as `abi_def`.  See GetRawABIResp.DecodeABI.
+func (api *API) GetRawABI(account AccountName) (out *GetRawABIResp, err error) {
+	return api.GetRawABIContext(context.Background(), account)
+}
+
+func (api *API) GetRawABIContext(ctx context.Context, account AccountName) (out *GetRawABIResp, err error) {
+	err = api.call(ctx, "chain", "get_raw_abi", M{"account_name": account}, &out)
 	return
 }
 
 // WalletImportKey loads a new WIF-encoded key into the wallet.
 func (api *API) WalletImportKey(walletName, wifPrivKey string) (err error) {
-	return api.call("wallet", "import_key", []string{walletName, wifPrivKey}, nil)
+	return api.WalletImportKeyContext(context.Background(), walletName, wifPrivKey)
+}
+
+func (api *API) WalletImportKeyContext(ctx context.Context, walletName, wifPrivKey string) (err error) {
+	return api.call(ctx, "wallet", "import_key", []string{walletName, wifPrivKey}, nil)
+}
+
+// WalletCreate creates a new wallet, and returns its password.  The
+// wallet is left open and unlocked.
+func (api *API) WalletCreate(walletName string) (password string, err error) {
+	return api.WalletCreateContext(context.Background(), walletName)
+}
+
+func (api *API) WalletCreateContext(ctx context.Context, walletName string) (password string, err error) {
+	err = api.call(ctx, "wallet", "create", walletName, &password)
+	return
+}
+
+func (api *API) WalletOpen(walletName string) (err error) {
+	return api.WalletOpenContext(context.Background(), walletName)
+}
+
+func (api *API) WalletOpenContext(ctx context.Context, walletName string) (err error) {
+	return api.call(ctx, "wallet", "open", walletName, nil)
+}
+
+func (api *API) WalletLock(walletName string) (err error) {
+	return api.WalletLockContext(context.Background(), walletName)
+}
+
+func (api *API) WalletLockContext(ctx context.Context, walletName string) (err error) {
+	return api.call(ctx, "wallet", "lock", walletName, nil)
+}
+
+func (api *API) WalletLockAll() (err error) {
+	return api.WalletLockAllContext(context.Background())
+}
+
+func (api *API) WalletLockAllContext(ctx context.Context) (err error) {
+	return api.call(ctx, "wallet", "lock_all", nil, nil)
+}
+
+// WalletUnlock unlocks a wallet, opening it first if needed.
+// Unlocking a wallet already unlocked fails with ErrWalletUnlocked.
+func (api *API) WalletUnlock(walletName, password string) (err error) {
+	return api.WalletUnlockContext(context.Background(), walletName, password)
+}
+
+func (api *API) WalletUnlockContext(ctx context.Context, walletName, password string) (err error) {
+	return api.call(ctx, "wallet", "unlock", []string{walletName, password}, nil)
+}
+
+// WalletList returns the open wallets.
+func (api *API) WalletList() (out []WalletStatus, err error) {
+	return api.WalletListContext(context.Background())
+}
+
+func (api *API) WalletListContext(ctx context.Context) (out []WalletStatus, err error) {
+	var names []string
+	err = api.call(ctx, "wallet", "list_wallets", nil, &names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		// Unlocked wallets are listed as "name *"
+		out = append(out, WalletStatus{
+			Name:     strings.TrimSuffix(name, " *"),
+			Unlocked: strings.HasSuffix(name, " *"),
+		})
+	}
+	return
+}
+
+// WalletCreateKey creates a new key in an unlocked wallet. `keyType`
+// is "K1" or "R1", K1 being the default when empty.
+func (api *API) WalletCreateKey(walletName, keyType string) (out ecc.PublicKey, err error) {
+	return api.WalletCreateKeyContext(context.Background(), walletName, keyType)
+}
+
+func (api *API) WalletCreateKeyContext(ctx context.Context, walletName, keyType string) (out ecc.PublicKey, err error) {
+	if keyType == "" {
+		keyType = "K1"
+	}
+
+	var textKey string
+	err = api.call(ctx, "wallet", "create_key", []string{walletName, keyType}, &textKey)
+	if err != nil {
+		return
+	}
+	return ecc.NewPublicKey(textKey)
+}
+
+func (api *API) WalletRemoveKey(walletName, password string, pubKey ecc.PublicKey) (err error) {
+	return api.WalletRemoveKeyContext(context.Background(), walletName, password, pubKey)
+}
+
+func (api *API) WalletRemoveKeyContext(ctx context.Context, walletName, password string, pubKey ecc.PublicKey) (err error) {
+	return api.call(ctx, "wallet", "remove_key", []string{walletName, password, pubKey.String()}, nil)
+}
+
+// WalletSetTimeout sets the inactivity delay after which `keosd` locks
+// all the wallets. It has a precision of one second.
+func (api *API) WalletSetTimeout(timeout time.Duration) (err error) {
+	return api.WalletSetTimeoutContext(context.Background(), timeout)
+}
+
+func (api *API) WalletSetTimeoutContext(ctx context.Context, timeout time.Duration) (err error) {
+	return api.call(ctx, "wallet", "set_timeout", int64(timeout/time.Second), nil)
+}
+
+// WalletSignDigest signs a 32 bytes digest with the private key of
+// `pubKey`, held in any unlocked wallet.
+func (api *API) WalletSignDigest(digest []byte, pubKey ecc.PublicKey) (out ecc.Signature, err error) {
+	return api.WalletSignDigestContext(context.Background(), digest, pubKey)
+}
+
+func (api *API) WalletSignDigestContext(ctx context.Context, digest []byte, pubKey ecc.PublicKey) (out ecc.Signature, err error) {
+	var textSig string
+	err = api.call(ctx, "wallet", "sign_digest", []string{hex.EncodeToString(digest), pubKey.String()}, &textSig)
+	if err != nil {
+		return
+	}
+	return ecc.NewSignature(textSig)
 }
 
 func (api *API) WalletPublicKeys() (out []ecc.PublicKey, err error) {
+	return api.WalletPublicKeysContext(context.Background())
+}
+
+func (api *API) WalletPublicKeysContext(ctx context.Context) (out []ecc.PublicKey, err error) {
 	var textKeys []string
-	err = api.call("wallet", "get_public_keys", nil, &textKeys)
+	err = api.call(ctx, "wallet", "get_public_keys", nil, &textKeys)
 	if err != nil {
 		return nil, err
 	}
@@ -164,8 +510,12 @@ func (api *API) WalletPublicKeys() (out []ecc.PublicKey, err error) {
 }
 
 func (api *API) ListKeys() (out []*ecc.PrivateKey, err error) {
+	return api.ListKeysContext(context.Background())
+}
+
+func (api *API) ListKeysContext(ctx context.Context) (out []*ecc.PrivateKey, err error) {
 	var textKeys []string
-	err = api.call("wallet", "list_keys", nil, &textKeys)
+	err = api.call(ctx, "wallet", "list_keys", nil, &textKeys)
 	if err != nil {
 		return nil, err
 	}
@@ -182,12 +532,16 @@ func (api *API) ListKeys() (out []*ecc.PrivateKey, err error) {
 }
 
 func (api *API) WalletSignTransaction(tx *SignedTransaction, chainID []byte, pubKeys ...ecc.PublicKey) (out *WalletSignTransactionResp, err error) {
+	return api.WalletSignTransactionContext(context.Background(), tx, chainID, pubKeys...)
+}
+
+func (api *API) WalletSignTransactionContext(ctx context.Context, tx *SignedTransaction, chainID []byte, pubKeys ...ecc.PublicKey) (out *WalletSignTransactionResp, err error) {
 	var textKeys []string
 	for _, key := range pubKeys {
 		textKeys = append(textKeys, key.String())
 	}
 
-	err = api.call("wallet", "sign_transaction", []interface{}{
+	err = api.call(ctx, "wallet", "sign_transaction", []interface{}{
 		tx,
 		textKeys,
 		hex.EncodeToString(chainID),
@@ -202,29 +556,41 @@ func (api *API) SignPushActions(a ...*Action) (out *PushTransactionFullResp, err
 	return api.SignPushActionsWithOpts(a, nil)
 }
 
+func (api *API) SignPushActionsContext(ctx context.Context, a ...*Action) (out *PushTransactionFullResp, err error) {
+	return api.SignPushActionsWithOptsContext(ctx, a, nil)
+}
+
 func (api *API) SignPushActionsWithOpts(actions []*Action, opts *TxOptions) (out *PushTransactionFullResp, err error) {
+	return api.SignPushActionsWithOptsContext(context.Background(), actions, opts)
+}
+
+func (api *API) SignPushActionsWithOptsContext(ctx context.Context, actions []*Action, opts *TxOptions) (out *PushTransactionFullResp, err error) {
 	if opts == nil {
 		opts = &TxOptions{}
 	}
 
-	if err := opts.FillFromChain(api); err != nil {
+	if err := opts.FillFromChainContext(ctx, api); err != nil {
 		return nil, err
 	}
 
 	tx := NewTransaction(actions, opts)
 
-	return api.SignPushTransaction(tx, opts.ChainID, opts.Compress)
+	return api.SignPushTransactionContext(ctx, tx, opts.ChainID, opts.Compress)
 }
 
 // SignPushTransaction will sign a transaction and submit it to the
 // chain.
 func (api *API) SignPushTransaction(tx *Transaction, chainID SHA256Bytes, compression CompressionType) (out *PushTransactionFullResp, err error) {
-	_, packed, err := api.SignTransaction(tx, chainID, compression)
+	return api.SignPushTransactionContext(context.Background(), tx, chainID, compression)
+}
+
+func (api *API) SignPushTransactionContext(ctx context.Context, tx *Transaction, chainID SHA256Bytes, compression CompressionType) (out *PushTransactionFullResp, err error) {
+	_, packed, err := api.SignTransactionContext(ctx, tx, chainID, compression)
 	if err != nil {
 		return nil, err
 	}
 
-	return api.PushTransaction(packed)
+	return api.PushTransactionContext(ctx, packed)
 }
 
 // SignTransaction will sign and pack a transaction, but not submit to
@@ -238,30 +604,27 @@ func (api *API) SignPushTransaction(tx *Transaction, chainID SHA256Bytes, compre
 // To sign a transaction, you need a Signer defined on the `API`
 // object. See SetSigner.
 func (api *API) SignTransaction(tx *Transaction, chainID SHA256Bytes, compression CompressionType) (*SignedTransaction, *PackedTransaction, error) {
+	return api.SignTransactionContext(context.Background(), tx, chainID, compression)
+}
+
+// SignTransactionContext is like SignTransaction, but `ctx` is
+// passed down to the `get_required_keys` call and, if the Signer
+// implements ContextSigner, to the signer itself.
+func (api *API) SignTransactionContext(ctx context.Context, tx *Transaction, chainID SHA256Bytes, compression CompressionType) (*SignedTransaction, *PackedTransaction, error) {
 	if api.Signer == nil {
 		return nil, nil, fmt.Errorf("no Signer configured")
 	}
 
 	stx := NewSignedTransaction(tx)
 
-	var requiredKeys []ecc.PublicKey
-	if api.customGetRequiredKeys != nil {
-		var err error
-		requiredKeys, err = api.customGetRequiredKeys(tx)
-		if err != nil {
-			return nil, nil, fmt.Errorf("custom_get_required_keys: %s", err)
-		}
-	} else {
-		resp, err := api.GetRequiredKeys(tx)
-		if err != nil {
-			return nil, nil, fmt.Errorf("get_required_keys: %s", err)
-		}
-		requiredKeys = resp.RequiredKeys
+	requiredKeys, err := api.requiredKeys(ctx, tx)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	signedTx, err := api.Signer.Sign(stx, chainID, requiredKeys...)
+	signedTx, err := signWithContext(ctx, api.Signer, stx, chainID, requiredKeys...)
 	if err != nil {
-		return nil, nil, fmt.Errorf("signing through wallet: %s", err)
+		return nil, nil, fmt.Errorf("signing through wallet: %w", err)
 	}
 
 	packed, err := signedTx.Pack(compression)
@@ -272,173 +635,373 @@ func (api *API) SignTransaction(tx *Transaction, chainID SHA256Bytes, compressio
 	return signedTx, packed, nil
 }
 
+// requiredKeys returns the keys signing `tx`, see SignTransaction.
+func (api *API) requiredKeys(ctx context.Context, tx *Transaction) ([]ecc.PublicKey, error) {
+	if api.customGetRequiredKeys != nil {
+		keys, err := api.customGetRequiredKeys(tx)
+		if err != nil {
+			return nil, fmt.Errorf("custom_get_required_keys: %w", err)
+		}
+		return keys, nil
+	}
+
+	resp, err := api.GetRequiredKeysContext(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("get_required_keys: %w", err)
+	}
+	return resp.RequiredKeys, nil
+}
+
 // PushTransaction submits a properly filled (tapos), packed and
 // signed transaction to the blockchain.
 func (api *API) PushTransaction(tx *PackedTransaction) (out *PushTransactionFullResp, err error) {
-	err = api.call("chain", "push_transaction", tx, &out)
-	return
+	return api.PushTransactionContext(context.Background(), tx)
 }
 
-func (api *API) GetInfo() (out *InfoResp, err error) {
-	err = api.call("chain", "get_info", nil, &out)
+func (api *API) PushTransactionContext(ctx context.Context, tx *PackedTransaction) (out *PushTransactionFullResp, err error) {
+	err = api.pushTransaction(ctx, tx, &out)
 	return
 }
 
-func (api *API) cachedGetInfo() (*InfoResp, error) {
-	api.lastGetInfoLock.Lock()
-	defer api.lastGetInfoLock.Unlock()
+// ComputeTransaction runs a transaction on the node without
+// broadcasting it, nor checking its signatures, and returns its
+// traces.  The state changes are reverted.  See EstimateResources.
+func (api *API) ComputeTransaction(tx *PackedTransaction) (out *ComputeTransactionResp, err error) {
+	return api.ComputeTransactionContext(context.Background(), tx)
+}
 
-	var info *InfoResp
-	var err error
+func (api *API) ComputeTransactionContext(ctx context.Context, tx *PackedTransaction) (out *ComputeTransactionResp, err error) {
+	err = api.call(ctx, "chain", "compute_transaction", M{"transaction": tx}, &out)
+	return
+}
 
-	if !api.lastGetInfoStamp.IsZero() && time.Now().Add(-1*time.Second).Before(api.lastGetInfoStamp) {
-		info = api.lastGetInfo
-	} else {
-		info, err = api.GetInfo()
-		if err != nil {
-			return nil, err
-		}
-		api.lastGetInfoStamp = time.Now()
-		api.lastGetInfo = info
-	}
-	if err != nil {
-		return nil, err
-	}
+func (api *API) GetInfo() (out *InfoResp, err error) {
+	return api.GetInfoContext(context.Background())
+}
 
-	return api.lastGetInfo, nil
+func (api *API) GetInfoContext(ctx context.Context) (out *InfoResp, err error) {
+	err = api.call(ctx, "chain", "get_info", nil, &out)
+	return
 }
 
 func (api *API) GetNetConnections() (out []*NetConnectionsResp, err error) {
-	err = api.call("net", "connections", nil, &out)
+	return api.GetNetConnectionsContext(context.Background())
+}
+
+func (api *API) GetNetConnectionsContext(ctx context.Context) (out []*NetConnectionsResp, err error) {
+	err = api.call(ctx, "net", "connections", nil, &out)
 	return
 }
 
 func (api *API) NetConnect(host string) (out NetConnectResp, err error) {
-	err = api.call("net", "connect", host, &out)
+	return api.NetConnectContext(context.Background(), host)
+}
+
+func (api *API) NetConnectContext(ctx context.Context, host string) (out NetConnectResp, err error) {
+	err = api.call(ctx, "net", "connect", host, &out)
 	return
 }
 
 func (api *API) NetDisconnect(host string) (out NetDisconnectResp, err error) {
-	err = api.call("net", "disconnect", host, &out)
+	return api.NetDisconnectContext(context.Background(), host)
+}
+
+func (api *API) NetDisconnectContext(ctx context.Context, host string) (out NetDisconnectResp, err error) {
+	err = api.call(ctx, "net", "disconnect", host, &out)
 	return
 }
 
 func (api *API) GetNetStatus(host string) (out *NetStatusResp, err error) {
-	err = api.call("net", "status", M{"host": host}, &out)
+	return api.GetNetStatusContext(context.Background(), host)
+}
+
+func (api *API) GetNetStatusContext(ctx context.Context, host string) (out *NetStatusResp, err error) {
+	err = api.call(ctx, "net", "status", M{"host": host}, &out)
 	return
 }
 
 func (api *API) GetBlockByID(id string) (out *BlockResp, err error) {
-	err = api.call("chain", "get_block", M{"block_num_or_id": id}, &out)
+	return api.GetBlockByIDContext(context.Background(), id)
+}
+
+func (api *API) GetBlockByIDContext(ctx context.Context, id string) (out *BlockResp, err error) {
+	err = api.call(ctx, "chain", "get_block", M{"block_num_or_id": id}, &out)
 	return
 }
 
 func (api *API) GetProducers() (out *ProducersResp, err error) {
+	return api.GetProducersContext(context.Background())
+}
+
+func (api *API) GetProducersContext(ctx context.Context) (out *ProducersResp, err error) {
 	/*
 		+FC_REFLECT( eosio::chain_apis::read_only::get_producers_params, (json)(lower_bound)(limit) )
 		+FC_REFLECT( eosio::chain_apis::read_only::get_producers_result, (rows)(total_producer_vote_weight)(more) ); */
-	err = api.call("chain", "get_producers", nil, &out)
+	err = api.call(ctx, "chain", "get_producers", nil, &out)
 	return
 }
 
 func (api *API) GetBlockByNum(num uint32) (out *BlockResp, err error) {
-	err = api.call("chain", "get_block", M{"block_num_or_id": fmt.Sprintf("%d", num)}, &out)
+	return api.GetBlockByNumContext(context.Background(), num)
+}
+
+func (api *API) GetBlockByNumContext(ctx context.Context, num uint32) (out *BlockResp, err error) {
+	err = api.call(ctx, "chain", "get_block", M{"block_num_or_id": fmt.Sprintf("%d", num)}, &out)
 	//err = api.call("chain", "get_block", M{"block_num_or_id": num}, &out)
 	return
 }
 
 func (api *API) GetBlockByNumOrID(query string) (out *SignedBlock, err error) {
-	err = api.call("chain", "get_block", M{"block_num_or_id": query}, &out)
+	return api.GetBlockByNumOrIDContext(context.Background(), query)
+}
+
+func (api *API) GetBlockByNumOrIDContext(ctx context.Context, query string) (out *SignedBlock, err error) {
+	err = api.call(ctx, "chain", "get_block", M{"block_num_or_id": query}, &out)
 	return
 }
 
 func (api *API) GetBlockByNumOrIDRaw(query string) (out interface{}, err error) {
-	err = api.call("chain", "get_block", M{"block_num_or_id": query}, &out)
+	return api.GetBlockByNumOrIDRawContext(context.Background(), query)
+}
+
+func (api *API) GetBlockByNumOrIDRawContext(ctx context.Context, query string) (out interface{}, err error) {
+	err = api.call(ctx, "chain", "get_block", M{"block_num_or_id": query}, &out)
 	return
 }
 
 func (api *API) GetTransaction(id string) (out *TransactionResp, err error) {
-	err = api.call("history", "get_transaction", M{"id": id}, &out)
+	return api.GetTransactionContext(context.Background(), id)
+}
+
+func (api *API) GetTransactionContext(ctx context.Context, id string) (out *TransactionResp, err error) {
+	err = api.call(ctx, "history", "get_transaction", M{"id": id}, &out)
 	return
 }
 
 func (api *API) GetTransactions(name AccountName) (out *TransactionsResp, err error) {
-	err = api.call("account_history", "get_transactions", M{"account_name": name}, &out)
+	return api.GetTransactionsContext(context.Background(), name)
+}
+
+func (api *API) GetTransactionsContext(ctx context.Context, name AccountName) (out *TransactionsResp, err error) {
+	err = api.call(ctx, "account_history", "get_transactions", M{"account_name": name}, &out)
+	return
+}
+
+// GetActions returns the actions of an account from the
+// `history_api_plugin`, between account sequences `pos` and
+// `pos+offset`.  Action data of types registered with RegisterAction
+// is decoded into them, failures being reported in the DecodeErr of
+// their trace. See IterateActions to walk through the whole history.
+func (api *API) GetActions(params GetActionsRequest) (out *GetActionsResp, err error) {
+	return api.GetActionsContext(context.Background(), params)
+}
+
+func (api *API) GetActionsContext(ctx context.Context, params GetActionsRequest) (out *GetActionsResp, err error) {
+	err = api.call(ctx, "history", "get_actions", params, &out)
+	if err != nil {
+		return
+	}
+
+	for idx := range out.Actions {
+		out.Actions[idx].Trace.mapToRegisteredActions()
+	}
 	return
 }
 
+// GetKeyAccounts returns the accounts having `publicKey` in one of
+// their permissions, from the `history_api_plugin`.
+func (api *API) GetKeyAccounts(publicKey ecc.PublicKey) (out []AccountName, err error) {
+	return api.GetKeyAccountsContext(context.Background(), publicKey)
+}
+
+func (api *API) GetKeyAccountsContext(ctx context.Context, publicKey ecc.PublicKey) (out []AccountName, err error) {
+	var resp *KeyAccountsResp
+	err = api.call(ctx, "history", "get_key_accounts", M{"public_key": publicKey}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.AccountNames, nil
+}
+
+// GetControlledAccounts returns the accounts having `controllingAccount`
+// in one of their permissions, from the `history_api_plugin`.
+func (api *API) GetControlledAccounts(controllingAccount AccountName) (out []AccountName, err error) {
+	return api.GetControlledAccountsContext(context.Background(), controllingAccount)
+}
+
+func (api *API) GetControlledAccountsContext(ctx context.Context, controllingAccount AccountName) (out []AccountName, err error) {
+	var resp *ControlledAccountsResp
+	err = api.call(ctx, "history", "get_controlled_accounts", M{"controlling_account": controllingAccount}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.ControlledAccounts, nil
+}
+
+// GetAccountsByAuthorizers returns the permissions satisfied, in part
+// or in full, by any of `authorizers` or `keys`.  An authorizer with
+// an empty `Permission` matches all the permissions of its actor.
+func (api *API) GetAccountsByAuthorizers(authorizers []PermissionLevel, keys []ecc.PublicKey) (out []AccountAuthorizer, err error) {
+	return api.GetAccountsByAuthorizersContext(context.Background(), authorizers, keys)
+}
+
+func (api *API) GetAccountsByAuthorizersContext(ctx context.Context, authorizers []PermissionLevel, keys []ecc.PublicKey) (out []AccountAuthorizer, err error) {
+	accounts := []interface{}{}
+	for _, authorizer := range authorizers {
+		if authorizer.Permission == "" {
+			accounts = append(accounts, authorizer.Actor)
+		} else {
+			accounts = append(accounts, authorizer)
+		}
+	}
+	if keys == nil {
+		keys = []ecc.PublicKey{}
+	}
+
+	var resp *AccountsByAuthorizersResp
+	err = api.call(ctx, "chain", "get_accounts_by_authorizers", M{"accounts": accounts, "keys": keys}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Accounts, nil
+}
+
 func (api *API) GetTableRows(params GetTableRowsRequest) (out *GetTableRowsResp, err error) {
-	err = api.call("chain", "get_table_rows", params, &out)
+	return api.GetTableRowsContext(context.Background(), params)
+}
+
+func (api *API) GetTableRowsContext(ctx context.Context, params GetTableRowsRequest) (out *GetTableRowsResp, err error) {
+	err = api.call(ctx, "chain", "get_table_rows", params, &out)
+	return
+}
+
+// GetTableByScope lists the scopes holding rows for the tables of a
+// contract (or for one table, if `params.Table` is set).
+func (api *API) GetTableByScope(params GetTableByScopeRequest) (out *GetTableByScopeResp, err error) {
+	return api.GetTableByScopeContext(context.Background(), params)
+}
+
+func (api *API) GetTableByScopeContext(ctx context.Context, params GetTableByScopeRequest) (out *GetTableByScopeResp, err error) {
+	err = api.call(ctx, "chain", "get_table_by_scope", params, &out)
 	return
 }
 
 func (api *API) GetRequiredKeys(tx *Transaction) (out *GetRequiredKeysResp, err error) {
-	keys, err := api.Signer.AvailableKeys()
+	return api.GetRequiredKeysContext(context.Background(), tx)
+}
+
+func (api *API) GetRequiredKeysContext(ctx context.Context, tx *Transaction) (out *GetRequiredKeysResp, err error) {
+	keys, err := availableKeysWithContext(ctx, api.Signer)
 	if err != nil {
 		return nil, err
 	}
 
-	err = api.call("chain", "get_required_keys", M{"transaction": tx, "available_keys": keys}, &out)
+	err = api.call(ctx, "chain", "get_required_keys", M{"transaction": tx, "available_keys": keys}, &out)
 	return
 }
 
 func (api *API) GetCurrencyBalance(account AccountName, symbol string, code AccountName) (out []Asset, err error) {
+	return api.GetCurrencyBalanceContext(context.Background(), account, symbol, code)
+}
+
+func (api *API) GetCurrencyBalanceContext(ctx context.Context, account AccountName, symbol string, code AccountName) (out []Asset, err error) {
 	params := M{"account": account, "code": code}
 	if symbol != "" {
 		params["symbol"] = symbol
 	}
-	err = api.call("chain", "get_currency_balance", params, &out)
+	err = api.call(ctx, "chain", "get_currency_balance", params, &out)
+	return
+}
+
+// GetCurrencyStats returns the supply of `symbol` issued by the token
+// contract `code`, keyed by symbol.
+func (api *API) GetCurrencyStats(code AccountName, symbol string) (out map[string]CurrencyStats, err error) {
+	return api.GetCurrencyStatsContext(context.Background(), code, symbol)
+}
+
+func (api *API) GetCurrencyStatsContext(ctx context.Context, code AccountName, symbol string) (out map[string]CurrencyStats, err error) {
+	err = api.call(ctx, "chain", "get_currency_stats", M{"code": code, "symbol": symbol}, &out)
 	return
 }
 
 // See more here: libraries/chain/contracts/abi_serializer.cpp:58...
 
-func (api *API) call(baseAPI string, endpoint string, body interface{}, out interface{}) error {
-	jsonBody, err := enc(body)
-	if err != nil {
-		return err
+func (api *API) call(ctx context.Context, baseAPI string, endpoint string, body interface{}, out interface{}) error {
+	if api.Retry == nil || !isReadCall(baseAPI, endpoint) {
+		return api.callOnce(ctx, baseAPI, endpoint, body, out)
+	}
+
+	return api.Retry.do(ctx, func(attempt int) error {
+		return api.callOnce(ctx, baseAPI, endpoint, body, out)
+	})
+}
+
+func (api *API) callOnce(ctx context.Context, baseAPI string, endpoint string, body interface{}, out interface{}) error {
+	if api.Pool != nil {
+		return api.callPool(ctx, baseAPI, endpoint, body, out)
 	}
 
-	targetURL := fmt.Sprintf("%s/v1/%s/%s", api.BaseURL, baseAPI, endpoint)
-	req, err := http.NewRequest("POST", targetURL, jsonBody)
+	return api.doRequest(ctx, api.BaseURL, api.HttpClient, baseAPI, endpoint, body, out)
+}
+
+// doRequest does a single round-trip to `baseURL`.
+func (api *API) doRequest(ctx context.Context, baseURL string, client *http.Client, baseAPI string, endpoint string, body interface{}, out interface{}) (err error) {
+	call := &CallInfo{
+		BaseAPI:   baseAPI,
+		Endpoint:  endpoint,
+		URL:       fmt.Sprintf("%s/v1/%s/%s", baseURL, baseAPI, endpoint),
+		Sensitive: IsSensitiveCall(baseAPI, endpoint),
+	}
+
+	call.Request, err = enc(body)
 	if err != nil {
-		return fmt.Errorf("NewRequest: %s", err)
+		return err
 	}
 
+	api.interceptBefore(ctx, call)
 	if api.Debug {
-		// Useful when debugging API calls
-		requestDump, err := httputil.DumpRequest(req, true)
-		if err != nil {
-			fmt.Println(err)
+		api.logger().Debug("api request", "url", call.URL, "body", string(call.RedactedRequest()))
+	}
+
+	start := time.Now()
+	defer func() {
+		call.Duration = time.Since(start)
+		call.Err = err
+		if api.Debug {
+			api.logger().Debug("api response", "url", call.URL, "status", call.StatusCode, "duration", call.Duration, "body", string(call.RedactedResponse()), "err", err)
 		}
-		fmt.Println("-------------------------------")
-		fmt.Println(string(requestDump))
-		fmt.Println("")
+		api.interceptAfter(ctx, call)
+	}()
+
+	var reqBody io.Reader
+	if call.Request != nil {
+		reqBody = bytes.NewReader(call.Request)
 	}
 
-	resp, err := api.HttpClient.Do(req)
+	req, err := http.NewRequestWithContext(ctx, "POST", call.URL, reqBody)
 	if err != nil {
-		return fmt.Errorf("%s: %s", req.URL.String(), err)
+		return fmt.Errorf("NewRequest: %s", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &TransportError{URL: req.URL.String(), Err: err}
 	}
 	defer resp.Body.Close()
+	call.StatusCode = resp.StatusCode
 
 	var cnt bytes.Buffer
 	_, err = io.Copy(&cnt, resp.Body)
 	if err != nil {
-		return fmt.Errorf("Copy: %s", err)
+		return &TransportError{URL: req.URL.String(), Err: fmt.Errorf("Copy: %w", err)}
 	}
+	call.Response = cnt.Bytes()
 
 	if resp.StatusCode == 404 {
 		return ErrNotFound
 	}
 	if resp.StatusCode > 299 {
-		return fmt.Errorf("%s: status code=%d, body=%s", req.URL.String(), resp.StatusCode, cnt.String())
-	}
-
-	if api.Debug {
-		fmt.Println("RESPONSE:")
-		fmt.Println(cnt.String())
-		fmt.Println("")
+		return newAPIError(resp.StatusCode, cnt.Bytes())
 	}
 
 	if err := json.Unmarshal(cnt.Bytes(), &out); err != nil {
@@ -450,19 +1013,28 @@ func (api *API) call(baseAPI string, endpoint string, body interface{}, out inte
 
 var ErrNotFound = errors.New("resource not found")
 
+// TransportError is returned when a call didn't get an answer from
+// the node (connection refused or reset, timeouts, truncated
+// bodies..), as opposed to an APIError, where `nodeos` did answer.
+type TransportError struct {
+	URL string
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("%s: %s", e.URL, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
 type M map[string]interface{}
 
-func enc(v interface{}) (io.Reader, error) {
+func enc(v interface{}) ([]byte, error) {
 	if v == nil {
 		return nil, nil
 	}
 
-	cnt, err := json.Marshal(v)
-	if err != nil {
-		return nil, err
-	}
-
-	//fmt.Println("BODY", string(cnt))
-
-	return bytes.NewReader(cnt), nil
+	return json.Marshal(v)
 }
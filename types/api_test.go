@@ -0,0 +1,283 @@
+package types_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Akagi201/eosgo/ecc"
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_GetInfoContext_Deadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	api := types.New(server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := api.GetInfoContext(ctx)
+	require.Error(t, err)
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestAPI_GetInfoContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chain/get_info", r.URL.Path)
+		w.Write([]byte(`{"server_version":"f537bc50","head_block_num":9,"last_irreversible_block_num":8}`))
+	}))
+	defer server.Close()
+
+	api := types.New(server.URL)
+
+	info, err := api.GetInfoContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "f537bc50", info.ServerVersion)
+	assert.Equal(t, uint32(9), info.HeadBlockNum)
+}
+
+func TestAPI_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+		w.Write([]byte(`{"code":500,"message":"Internal Service Error","error":{"code":3050003,"name":"eosio_assert_message_exception","what":"eosio_assert_message assertion failure","details":[{"message":"assertion failure with message: overdrawn balance","file":"wasm_interface.cpp","line_number":933,"method":"eosio_assert"}]}}`))
+	}))
+	defer server.Close()
+
+	api := types.New(server.URL)
+
+	_, err := api.GetInfo()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, types.ErrAssertMessage))
+	assert.False(t, errors.Is(err, types.ErrTxDuplicate))
+
+	var apiErr *types.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 500, apiErr.Code)
+	assert.Equal(t, 3050003, apiErr.ErrorStruct.Code)
+	assert.Equal(t, "eosio_assert", apiErr.ErrorStruct.Details[0].Method)
+
+	msg, ok := apiErr.AssertMessage()
+	assert.True(t, ok)
+	assert.Equal(t, "overdrawn balance", msg)
+}
+
+func TestAPI_APIError_NotJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(502)
+		w.Write([]byte("<html>Bad Gateway</html>"))
+	}))
+	defer server.Close()
+
+	api := types.New(server.URL)
+
+	_, err := api.GetInfo()
+	var apiErr *types.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 502, apiErr.Code)
+	assert.Equal(t, "status code=502, <html>Bad Gateway</html>", apiErr.Error())
+}
+
+func TestAPI_Interceptors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var trace []string
+	var after *types.CallInfo
+	api := types.New(server.URL)
+	api.Use(
+		types.InterceptorFuncs{
+			BeforeFunc: func(ctx context.Context, call *types.CallInfo) { trace = append(trace, "before 1") },
+			AfterFunc: func(ctx context.Context, call *types.CallInfo) {
+				trace = append(trace, "after 1")
+				after = call
+			},
+		},
+		types.InterceptorFuncs{
+			BeforeFunc: func(ctx context.Context, call *types.CallInfo) { trace = append(trace, "before 2") },
+			AfterFunc:  func(ctx context.Context, call *types.CallInfo) { trace = append(trace, "after 2") },
+		},
+	)
+
+	require.NoError(t, api.WalletImportKey("default", "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"))
+
+	assert.Equal(t, []string{"before 1", "before 2", "after 2", "after 1"}, trace)
+	assert.Equal(t, "wallet", after.BaseAPI)
+	assert.Equal(t, "import_key", after.Endpoint)
+	assert.Equal(t, 200, after.StatusCode)
+	assert.NoError(t, after.Err)
+	assert.True(t, after.Sensitive)
+	assert.Contains(t, string(after.Request), "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3")
+	assert.NotContains(t, string(after.RedactedRequest()), "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3")
+}
+
+func TestAPI_DebugLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"server_version":"f537bc50"}`))
+	}))
+	defer server.Close()
+
+	var messages []string
+	api := types.New(server.URL)
+	api.Debug = true
+	api.Logger = types.LoggerFunc(func(msg string, keyvals ...interface{}) {
+		messages = append(messages, msg)
+	})
+
+	_, err := api.GetInfo()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api request", "api response"}, messages)
+}
+
+func TestAPI_AccountsLookup(t *testing.T) {
+	const pubKey = "EOS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV"
+
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v1/history/get_key_accounts":
+			w.Write([]byte(`{"account_names":["alice","bob"]}`))
+		case "/v1/history/get_controlled_accounts":
+			w.Write([]byte(`{"controlled_accounts":["carol"]}`))
+		case "/v1/chain/get_accounts_by_authorizers":
+			w.Write([]byte(`{"accounts":[
+				{"account_name":"alice","permission_name":"active","authorizing_account":null,"authorizing_key":"` + pubKey + `","weight":1,"threshold":1},
+				{"account_name":"carol","permission_name":"owner","authorizing_account":{"actor":"bob","permission":"active"},"authorizing_key":null,"weight":1,"threshold":2}
+			]}`))
+		default:
+			t.Errorf("unexpected call to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	api := types.New(server.URL)
+	key, err := ecc.NewPublicKey(pubKey)
+	require.NoError(t, err)
+
+	accounts, err := api.GetKeyAccounts(key)
+	require.NoError(t, err)
+	assert.Equal(t, []types.AccountName{"alice", "bob"}, accounts)
+	assert.Equal(t, pubKey, body["public_key"])
+
+	accounts, err = api.GetControlledAccounts("bob")
+	require.NoError(t, err)
+	assert.Equal(t, []types.AccountName{"carol"}, accounts)
+	assert.Equal(t, "bob", body["controlling_account"])
+
+	authorizers, err := api.GetAccountsByAuthorizers([]types.PermissionLevel{{Actor: "bob"}, {Actor: "dave", Permission: "active"}}, []ecc.PublicKey{key})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"bob", map[string]interface{}{"actor": "dave", "permission": "active"}}, body["accounts"])
+	assert.Equal(t, []interface{}{pubKey}, body["keys"])
+
+	require.Len(t, authorizers, 2)
+	assert.Nil(t, authorizers[0].AuthorizingAccount)
+	require.NotNil(t, authorizers[0].AuthorizingKey)
+	assert.Equal(t, pubKey, authorizers[0].AuthorizingKey.String())
+	assert.Nil(t, authorizers[1].AuthorizingKey)
+	assert.Equal(t, &types.PermissionLevel{Actor: "bob", Permission: "active"}, authorizers[1].AuthorizingAccount)
+	assert.Equal(t, uint32(2), authorizers[1].Threshold)
+}
+
+func TestAPI_Producer(t *testing.T) {
+	var bodies = map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies[r.URL.Path] = string(body)
+
+		switch r.URL.Path {
+		case "/v1/producer/get_runtime_options":
+			w.Write([]byte(`{"max_transaction_time":30,"max_irreversible_block_age":-1,"produce_time_offset_us":0,"last_block_time_offset_us":0,"max_scheduled_transaction_time_per_block_ms":100,"subjective_cpu_leeway_us":31000,"incoming_defer_ratio":"1.00000000000000000"}`))
+		case "/v1/producer/get_greylist":
+			w.Write([]byte(`{"accounts":["alice"]}`))
+		case "/v1/producer/get_whitelist_blacklist":
+			w.Write([]byte(`{"actor_whitelist":[],"actor_blacklist":["mallory"],"contract_whitelist":[],"contract_blacklist":[],"action_blacklist":[["eosio.token","transfer"]],"key_blacklist":[]}`))
+		case "/v1/producer/create_snapshot":
+			w.Write([]byte(`{"head_block_id":"0000000a9bde2a6b4e1d00a21a8fe1ff82ed4de3c4ff0c8d0d2fa0c6c6d58e81","head_block_num":10,"head_block_time":"2020-01-20T08:00:05.000","version":3,"snapshot_name":"/data/snapshots/snapshot-0000000a.bin"}`))
+		case "/v1/producer/get_integrity_hash":
+			w.Write([]byte(`{"head_block_id":"0000000a9bde2a6b4e1d00a21a8fe1ff82ed4de3c4ff0c8d0d2fa0c6c6d58e81","integrity_hash":"e6b3cd1fa5ad2e49e3b0d2f3bce3e7ab2c5f6b27cbcd0a8f46a84c2c5e5bbd41"}`))
+		case "/v1/producer/get_scheduled_protocol_feature_activations":
+			w.Write([]byte(`{"protocol_features_to_activate":["0ec7e080177b2c02b278d5088611686b49d739925a92d9bfcacd7fc6b74053bd"]}`))
+		case "/v1/producer/get_supported_protocol_features":
+			w.Write([]byte(`[{"feature_digest":"0ec7e080177b2c02b278d5088611686b49d739925a92d9bfcacd7fc6b74053bd","subjective_restrictions":{"enabled":true,"preactivation_required":false,"earliest_allowed_activation_time":"1970-01-01T00:00:00.000"},"description_digest":"64fe7df32e9b86be2b296b3f81dfd527f84e82b98e363bc97e40bc7a83733310","dependencies":[],"protocol_feature_type":"builtin","specification":[{"name":"builtin_feature_codename","value":"PREACTIVATE_FEATURE"}]}]`))
+		default:
+			w.Write([]byte(`{"result":"ok"}`))
+		}
+	}))
+	defer server.Close()
+
+	api := types.New(server.URL)
+
+	opts, err := api.GetProducerRuntimeOptions()
+	require.NoError(t, err)
+	require.NotNil(t, opts.MaxTransactionTime)
+	assert.Equal(t, int32(30), *opts.MaxTransactionTime)
+	assert.Nil(t, opts.GreylistLimit)
+
+	maxTime := int32(50)
+	require.NoError(t, api.UpdateProducerRuntimeOptions(types.ProducerRuntimeOptions{MaxTransactionTime: &maxTime}))
+	assert.JSONEq(t, `{"max_transaction_time":50}`, bodies["/v1/producer/update_runtime_options"])
+
+	greylist, err := api.GetGreylist()
+	require.NoError(t, err)
+	assert.Equal(t, []types.AccountName{"alice"}, greylist)
+	require.NoError(t, api.AddGreylistAccounts("bob", "carol"))
+	assert.JSONEq(t, `{"accounts":["bob","carol"]}`, bodies["/v1/producer/add_greylist_accounts"])
+	require.NoError(t, api.RemoveGreylistAccounts("alice"))
+	assert.JSONEq(t, `{"accounts":["alice"]}`, bodies["/v1/producer/remove_greylist_accounts"])
+
+	lists, err := api.GetWhitelistBlacklist()
+	require.NoError(t, err)
+	assert.Equal(t, []types.AccountName{"mallory"}, lists.ActorBlacklist)
+	assert.Equal(t, []types.BlacklistedAction{{Account: "eosio.token", Action: "transfer"}}, lists.ActionBlacklist)
+
+	require.NoError(t, api.SetWhitelistBlacklist(types.WhitelistBlacklist{
+		ActorBlacklist:  []types.AccountName{},
+		ActionBlacklist: []types.BlacklistedAction{{Account: "eosio", Action: "setcode"}},
+	}))
+	assert.JSONEq(t, `{"actor_blacklist":[],"action_blacklist":[["eosio","setcode"]]}`, bodies["/v1/producer/set_whitelist_blacklist"])
+
+	snapshot, err := api.CreateSnapshot()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), snapshot.HeadBlockNum)
+	assert.Equal(t, "/data/snapshots/snapshot-0000000a.bin", snapshot.SnapshotName)
+
+	hash, err := api.GetIntegrityHash()
+	require.NoError(t, err)
+	assert.Len(t, hash.IntegrityHash, 32)
+
+	scheduled, err := api.GetScheduledProtocolFeatureActivations()
+	require.NoError(t, err)
+	require.Len(t, scheduled, 1)
+
+	require.NoError(t, api.ScheduleProtocolFeatureActivations(scheduled...))
+	assert.JSONEq(t, `{"protocol_features_to_activate":["0ec7e080177b2c02b278d5088611686b49d739925a92d9bfcacd7fc6b74053bd"]}`, bodies["/v1/producer/schedule_protocol_feature_activations"])
+
+	features, err := api.GetSupportedProtocolFeatures(types.GetSupportedProtocolFeaturesRequest{ExcludeDisabled: true})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"exclude_disabled":true,"exclude_unactivatable":false}`, bodies["/v1/producer/get_supported_protocol_features"])
+	require.Len(t, features, 1)
+	assert.True(t, features[0].SubjectiveRestrictions.Enabled)
+	assert.Equal(t, "PREACTIVATE_FEATURE", features[0].Specification[0].Value)
+}
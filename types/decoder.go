@@ -64,20 +64,19 @@ type Decoder struct {
 	decodeActions      bool
 }
 
-//var prefix = make([]string, 0)
-
+// Debug turns on the tracing of the Encoder and the Decoder, sent to
+// the package Logger (see SetLogger).
 var Debug bool
 
 var print = func(s string) {
 	if Debug {
-		//for _, s := range prefix {
-		//fmt.Print(s)
-		//}
-		fmt.Print(s)
+		getLogger().Debug(s)
 	}
 }
 var println = func(args ...interface{}) {
-	print(fmt.Sprintf("%s\n", args...))
+	if Debug {
+		getLogger().Debug(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+	}
 }
 
 func NewDecoder(data []byte) *Decoder {
@@ -222,6 +221,11 @@ func (d *Decoder) Decode(v interface{}) (err error) {
 		asset, err = d.readAsset()
 		rv.Set(reflect.ValueOf(asset))
 		return
+	case *SetABI:
+		var setABI SetABI
+		setABI, err = d.readSetABI()
+		rv.Set(reflect.ValueOf(setABI))
+		return
 
 	case *TransactionWithID:
 
@@ -372,7 +376,12 @@ func (d *Decoder) decodeStruct(v interface{}, t reflect.Type, rv reflect.Value)
 	//prefix = append(prefix, "     ")
 	for i := 0; i < l; i++ {
 
-		if tag := t.Field(i).Tag.Get("eos"); tag == "-" {
+		tag := t.Field(i).Tag.Get("eos")
+		if tag == "-" {
+			continue
+		}
+		// Binary extensions are left out at the end of older data.
+		if tag == "binary_extension" && d.Remaining() == 0 {
 			continue
 		}
 
@@ -525,8 +534,8 @@ func (d *Decoder) ReadPublicKey() (out ecc.PublicKey, err error) {
 		return
 	}
 	out = ecc.PublicKey{
-		Curve:   ecc.CurveID(d.data[d.pos]),                 // 1 byte
-		Content: d.data[d.pos+1 : d.pos+TypeSize.PublicKey], // 33 bytes
+		Curve:   ecc.CurveID(d.data[d.pos]),                                            // 1 byte
+		Content: d.data[d.pos+1 : d.pos+TypeSize.PublicKey : d.pos+TypeSize.PublicKey], // 33 bytes, capped: String() appends to it
 	}
 	d.pos += TypeSize.PublicKey
 	println(fmt.Sprintf("ReadPublicKey [curve=%d, content=%s]", out.Curve, hex.EncodeToString(out.Content)))
@@ -539,8 +548,8 @@ func (d *Decoder) ReadSignature() (out ecc.Signature, err error) {
 		return
 	}
 	out = ecc.Signature{
-		Curve:   ecc.CurveID(d.data[d.pos]),                 // 1 byte
-		Content: d.data[d.pos+1 : d.pos+TypeSize.Signature], // 65 bytes
+		Curve:   ecc.CurveID(d.data[d.pos]),                                            // 1 byte
+		Content: d.data[d.pos+1 : d.pos+TypeSize.Signature : d.pos+TypeSize.Signature], // 65 bytes, capped: String() appends to it
 	}
 	d.pos += TypeSize.Signature
 	println(fmt.Sprintf("ReadSignature [curve=%d, content=%s]", out.Curve, hex.EncodeToString(out.Content)))
@@ -603,6 +612,25 @@ func (d *Decoder) readAsset() (out Asset, err error) {
 	return
 }
 
+// readSetABI reads the `setabi` action, which carries its ABI as
+// bytes.
+func (d *Decoder) readSetABI() (out SetABI, err error) {
+	n, err := d.ReadUint64()
+	if err != nil {
+		return out, fmt.Errorf("setabi account, %s", err)
+	}
+	out.Account = AccountName(NameToString(n))
+
+	data, err := d.ReadByteArray()
+	if err != nil {
+		return out, fmt.Errorf("setabi abi, %s", err)
+	}
+	if err = out.ABI.UnmarshalBinary(data); err != nil {
+		return out, fmt.Errorf("setabi abi, %s", err)
+	}
+	return
+}
+
 func (d *Decoder) readActionData(action *Action) (err error) {
 
 	actionMap := RegisteredActions[action.Account]
@@ -661,6 +689,17 @@ func (d *Decoder) readP2PMessageEnvelope() (out *P2PMessageEnvelope, err error)
 	return
 }
 
+func (d *Decoder) readBytes(n int) (out []byte, err error) {
+	if d.Remaining() < n {
+		err = fmt.Errorf("required [%d] bytes, remaining [%d]", n, d.Remaining())
+		return
+	}
+
+	out = d.data[d.pos : d.pos+n]
+	d.pos += n
+	return
+}
+
 func (d *Decoder) Remaining() int {
 	return len(d.data) - d.pos
 }
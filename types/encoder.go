@@ -100,6 +100,10 @@ func (e *Encoder) Encode(v interface{}) (err error) {
 		return e.writeCurrencyName(cv)
 	case Asset:
 		return e.writeAsset(cv)
+	case SetABI:
+		return e.writeSetABI(cv)
+	case *SetABI:
+		return e.writeSetABI(*cv)
 		// case *OptionalProducerSchedule:
 		// 	isPresent := cv != nil
 		// 	e.WriteBool(isPresent)
@@ -331,6 +335,20 @@ func (e *Encoder) writeAsset(asset Asset) (err error) {
 	return e.toWriter(symbol)
 }
 
+// writeSetABI writes the `setabi` action, which carries its ABI as
+// bytes.
+func (e *Encoder) writeSetABI(setABI SetABI) (err error) {
+	if err = e.WriteName(Name(setABI.Account)); err != nil {
+		return
+	}
+
+	abi, err := setABI.ABI.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("setabi abi, %s", err)
+	}
+	return e.WriteByteArray(abi)
+}
+
 func (e *Encoder) writeJSONTime(time JSONTime) (err error) {
 	return e.WriteUint32(uint32(time.Unix()))
 }
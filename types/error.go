@@ -0,0 +1,131 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// APIError represents the error envelope returned by `nodeos` on any
+// non-2xx response. It looks like:
+//
+//	{
+//	  "code": 500,
+//	  "message": "Internal Service Error",
+//	  "error": {
+//	    "code": 3040008,
+//	    "name": "tx_duplicate",
+//	    "what": "Duplicate transaction",
+//	    "details": [{"message": "...", "file": "producer_plugin.cpp", "line_number": 343, "method": "on_incoming_transaction_async"}]
+//	  }
+//	}
+//
+// Use `errors.Is(err, ErrTxDuplicate)` (or any of the other well-known
+// exception names below) to branch on the kind of failure, and
+// `errors.As` to get to the full details.
+type APIError struct {
+	Code        int    `json:"code"` // HTTP status code
+	Message     string `json:"message"`
+	ErrorStruct struct {
+		Code    int              `json:"code"` // `fc::exception` code, see libraries/chain/include/eosio/chain/exceptions.hpp
+		Name    string           `json:"name"`
+		What    string           `json:"what"`
+		Details []APIErrorDetail `json:"details"`
+	} `json:"error"`
+}
+
+type APIErrorDetail struct {
+	Message    string `json:"message"`
+	File       string `json:"file"`
+	LineNumber int    `json:"line_number"`
+	Method     string `json:"method"`
+}
+
+// newAPIError parses the body of a failed call.  Bodies that are not
+// a `nodeos` envelope (proxies in front of the node often answer with
+// HTML) are kept whole in `Message`.
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{}
+	if err := json.Unmarshal(body, apiErr); err != nil || (apiErr.Message == "" && apiErr.ErrorStruct.Name == "") {
+		apiErr = &APIError{Message: strings.TrimSpace(string(body))}
+	}
+	apiErr.Code = statusCode
+
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	if e.ErrorStruct.Name == "" {
+		return fmt.Sprintf("status code=%d, %s", e.Code, e.Message)
+	}
+
+	msg := fmt.Sprintf("%s: %s (code=%d)", e.ErrorStruct.Name, e.ErrorStruct.What, e.ErrorStruct.Code)
+	if len(e.ErrorStruct.Details) > 0 {
+		msg = fmt.Sprintf("%s: %s", msg, e.ErrorStruct.Details[0].Message)
+	}
+	return msg
+}
+
+// Is makes `errors.Is` match an APIError against the well-known
+// exception names, like `ErrTxDuplicate`.
+func (e *APIError) Is(target error) bool {
+	name, ok := target.(ExceptionName)
+	if !ok {
+		return false
+	}
+	return e.ErrorStruct.Name == string(name)
+}
+
+// AssertMessage returns the message passed to `eosio_assert` by the
+// contract, when the error is an `eosio_assert_message_exception`.
+func (e *APIError) AssertMessage() (string, bool) {
+	if e.ErrorStruct.Name != string(ErrAssertMessage) {
+		return "", false
+	}
+
+	const prefix = "assertion failure with message: "
+	for _, detail := range e.ErrorStruct.Details {
+		if strings.HasPrefix(detail.Message, prefix) {
+			return strings.TrimPrefix(detail.Message, prefix), true
+		}
+	}
+	return "", false
+}
+
+// ExceptionName is the `name` of a `nodeos` exception, usable as a
+// target for `errors.Is`.
+type ExceptionName string
+
+func (n ExceptionName) Error() string {
+	return string(n)
+}
+
+// See libraries/chain/include/eosio/chain/exceptions.hpp
+const (
+	ErrTxDuplicate              = ExceptionName("tx_duplicate")
+	ErrExpiredTx                = ExceptionName("expired_tx_exception")
+	ErrTxExpirationTooFar       = ExceptionName("tx_exp_too_far_exception")
+	ErrInvalidRefBlock          = ExceptionName("invalid_ref_block_exception")
+	ErrAssertMessage            = ExceptionName("eosio_assert_message_exception")
+	ErrAssertCode               = ExceptionName("eosio_assert_code_exception")
+	ErrRAMUsageExceeded         = ExceptionName("ram_usage_exceeded")
+	ErrTxNetUsageExceeded       = ExceptionName("tx_net_usage_exceeded")
+	ErrTxCPUUsageExceeded       = ExceptionName("tx_cpu_usage_exceeded")
+	ErrLeewayDeadline           = ExceptionName("leeway_deadline_exception")
+	ErrDeadline                 = ExceptionName("deadline_exception")
+	ErrUnsatisfiedAuthorization = ExceptionName("unsatisfied_authorization")
+	ErrMissingAuth              = ExceptionName("missing_auth_exception")
+	ErrIrrelevantAuth           = ExceptionName("irrelevant_auth_exception")
+	ErrUnknownBlock             = ExceptionName("unknown_block_exception")
+	ErrUnknownTransaction       = ExceptionName("unknown_transaction_exception")
+	ErrTxNotFound               = ExceptionName("tx_not_found")
+
+	// Wallet (`keosd`) exceptions
+	ErrWalletExist           = ExceptionName("wallet_exist_exception")
+	ErrWalletNonexistent     = ExceptionName("wallet_nonexistent_exception")
+	ErrWalletLocked          = ExceptionName("wallet_locked_exception")
+	ErrWalletUnlocked        = ExceptionName("wallet_unlocked_exception")
+	ErrWalletMissingPubKey   = ExceptionName("wallet_missing_pub_key_exception")
+	ErrWalletInvalidPassword = ExceptionName("wallet_invalid_password_exception")
+	ErrWalletNotAvailable    = ExceptionName("wallet_not_available_exception")
+)
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// ResourceEstimate is what a transaction was billed when run with
+// `compute_transaction`.
+type ResourceEstimate struct {
+	TransactionID        SHA256Bytes
+	CPUUsageMicroSeconds uint32
+
+	// NetUsageWords includes the signatures, which the dry run
+	// doesn't have: see EstimateResources.
+	NetUsageWords uint32
+
+	// RAMDeltas are the bytes of RAM used (or freed, if negative) by
+	// the transaction, per account billed.
+	RAMDeltas map[AccountName]int64
+}
+
+// EstimateResources builds the transaction of `actions` like
+// SignPushActionsWithOpts does, and dry runs it on the node with
+// ComputeTransaction, which doesn't need signatures.  The CPU and NET
+// limits of `opts` are ignored for the run. See ResourceEstimate.Apply
+// to set them from the estimate.
+//
+// `nodeos` bills the signatures as NET, so their size is added to the
+// NET of the run: one signature per key the transaction requires,
+// found like SignTransaction does when `api` has a Signer, or else one
+// per distinct authorization of the actions.
+//
+// The CPU billed depends on the load of the node at the time of the
+// run: give some margin.
+func (api *API) EstimateResources(actions []*Action, opts *TxOptions) (*ResourceEstimate, error) {
+	return api.EstimateResourcesContext(context.Background(), actions, opts)
+}
+
+func (api *API) EstimateResourcesContext(ctx context.Context, actions []*Action, opts *TxOptions) (*ResourceEstimate, error) {
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+	if err := opts.FillFromChainContext(ctx, api); err != nil {
+		return nil, err
+	}
+
+	unlimited := *opts
+	unlimited.MaxCPUUsageMS = 0
+	unlimited.MaxNetUsageWords = 0
+	tx := NewTransaction(actions, &unlimited)
+
+	packed, err := NewSignedTransaction(tx).Pack(opts.Compress)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := api.ComputeTransactionContext(ctx, packed)
+	if err != nil {
+		return nil, err
+	}
+
+	processed := resp.Processed
+	if len(processed.Except) != 0 && string(processed.Except) != "null" {
+		return nil, fmt.Errorf("compute_transaction: %s", processed.Except)
+	}
+	if processed.Receipt == nil {
+		return nil, fmt.Errorf("compute_transaction: no receipt in the trace")
+	}
+
+	signatures, err := api.signatureCount(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &ResourceEstimate{
+		TransactionID:        resp.TransactionID,
+		CPUUsageMicroSeconds: processed.Receipt.CPUUsageMicroSeconds,
+		NetUsageWords:        uint32(processed.Receipt.NetUsageWords) + (signatures*signatureSize+7)/8,
+		RAMDeltas:            map[AccountName]int64{},
+	}
+	for _, trace := range processed.ActionTraces {
+		for _, delta := range trace.AccountRAMDeltas {
+			estimate.RAMDeltas[delta.Account] += delta.Delta
+		}
+	}
+	if delta := processed.AccountRAMDelta; delta != nil {
+		estimate.RAMDeltas[delta.Account] += delta.Delta
+	}
+
+	return estimate, nil
+}
+
+// signatureSize is the packed size of a signature: its curve, and 65
+// bytes.
+const signatureSize = 66
+
+// signatureCount returns how many signatures `tx` will need.
+func (api *API) signatureCount(ctx context.Context, tx *Transaction) (uint32, error) {
+	if api.Signer != nil || api.customGetRequiredKeys != nil {
+		keys, err := api.requiredKeys(ctx, tx)
+		if err != nil {
+			return 0, err
+		}
+		return uint32(len(keys)), nil
+	}
+
+	authorizations := map[PermissionLevel]bool{}
+	for _, action := range append(append([]*Action{}, tx.ContextFreeActions...), tx.Actions...) {
+		for _, level := range action.Authorization {
+			authorizations[level] = true
+		}
+	}
+	return uint32(len(authorizations)), nil
+}
+
+// Apply sets the CPU and NET limits of `opts` to the estimate, plus a
+// `margin` (0.2 for 20% more).  The CPU limit is rounded up to the
+// next millisecond.  The NET limit covers the signatures, see
+// EstimateResources.
+func (e *ResourceEstimate) Apply(opts *TxOptions, margin float64) {
+	cpuMS := math.Ceil(float64(e.CPUUsageMicroSeconds) * (1 + margin) / 1000)
+	switch {
+	case cpuMS < 1:
+		cpuMS = 1
+	case cpuMS > math.MaxUint8:
+		cpuMS = math.MaxUint8
+	}
+	opts.MaxCPUUsageMS = uint8(cpuMS)
+
+	netWords := math.Ceil(float64(e.NetUsageWords) * (1 + margin))
+	if netWords > math.MaxUint32 {
+		netWords = math.MaxUint32
+	}
+	opts.MaxNetUsageWords = uint32(netWords)
+}
@@ -0,0 +1,60 @@
+package types_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_EstimateResources(t *testing.T) {
+	var computed struct {
+		Transaction types.PackedTransaction `json:"transaction"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/chain/get_info":
+			w.Write([]byte(`{"chain_id":"aca376f206b8fc25a6ed44dbdc66547c36c6c33e3a119ffbeaef943642f0e906","head_block_num":10,"head_block_id":"0000000a9bde2a6b4e1d00a21a8fe1ff82ed4de3c4ff0c8d0d2fa0c6c6d58e81","head_block_time":"2020-01-20T08:00:05.000"}`))
+		case "/v1/chain/compute_transaction":
+			body, _ := ioutil.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &computed))
+			w.Write([]byte(`{"transaction_id":"6e5b3e3a5a1f2f7de1b5e09b2b64bb8e9c5a0dbc5e4a8c1c5e7a3ea4d2a6b0c1","processed":{"id":"6e5b3e3a5a1f2f7de1b5e09b2b64bb8e9c5a0dbc5e4a8c1c5e7a3ea4d2a6b0c1","block_num":11,"block_time":"2020-01-20T08:00:05.500","producer_block_id":null,"receipt":{"status":"executed","cpu_usage_us":1450,"net_usage_words":16},"elapsed":1450,"net_usage":128,"scheduled":false,"action_traces":[{"action_ordinal":1,"creator_action_ordinal":0,"closest_unnotified_ancestor_action_ordinal":0,"receipt":null,"receiver":"eosio.token","act":{"account":"eosio.token","name":"transfer","authorization":[{"actor":"alice","permission":"active"}],"data":"0000000000855c340000000000000e3d102700000000000004454f530000000000"},"context_free":false,"elapsed":310,"console":"","trx_id":"6e5b3e3a5a1f2f7de1b5e09b2b64bb8e9c5a0dbc5e4a8c1c5e7a3ea4d2a6b0c1","block_num":11,"block_time":"2020-01-20T08:00:05.500","producer_block_id":null,"account_ram_deltas":[{"account":"bob","delta":240}],"except":null,"error_code":null},{"action_ordinal":2,"creator_action_ordinal":1,"receiver":"alice","act":{"account":"eosio.token","name":"transfer","authorization":[{"actor":"alice","permission":"active"}],"data":"0000000000855c340000000000000e3d102700000000000004454f530000000000"},"elapsed":5,"console":"","account_ram_deltas":[{"account":"alice","delta":-112},{"account":"bob","delta":16}],"except":null}],"account_ram_delta":null,"except":null,"error_code":null}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := types.New(server.URL)
+	opts := &types.TxOptions{MaxCPUUsageMS: 1, MaxNetUsageWords: 1}
+	estimate, err := api.EstimateResources([]*types.Action{{
+		Account:       "eosio.token",
+		Name:          "transfer",
+		Authorization: []types.PermissionLevel{{Actor: "alice", Permission: "active"}},
+		ActionData:    types.ActionData{Data: "0000000000855c340000000000000e3d102700000000000004454f530000000000"},
+	}}, opts)
+	require.NoError(t, err)
+
+	assert.Empty(t, computed.Transaction.Signatures, "compute_transaction doesn't need signatures")
+	tx, err := computed.Transaction.Unpack()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), uint32(tx.MaxCPUUsageMS), "limits are lifted for the run")
+	assert.Equal(t, uint16(10), tx.RefBlockNum)
+
+	assert.Equal(t, uint32(1450), estimate.CPUUsageMicroSeconds)
+	assert.Equal(t, uint32(16+9), estimate.NetUsageWords, "with the signature of alice@active")
+	assert.Equal(t, map[types.AccountName]int64{"alice": -112, "bob": 256}, estimate.RAMDeltas)
+
+	estimate.Apply(opts, 0.5)
+	assert.Equal(t, uint8(3), opts.MaxCPUUsageMS)
+	assert.Equal(t, uint32(38), opts.MaxNetUsageWords)
+
+	estimate.CPUUsageMicroSeconds = 10
+	estimate.Apply(opts, 0)
+	assert.Equal(t, uint8(1), opts.MaxCPUUsageMS)
+}
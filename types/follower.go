@@ -0,0 +1,209 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Checkpointer stores the last block handled by a BlockFollower, so it
+// resumes from there after a restart.
+type Checkpointer interface {
+	// Load returns the last block saved, or 0 if there's none.
+	Load(ctx context.Context) (blockNum uint32, err error)
+	Save(ctx context.Context, blockNum uint32) error
+}
+
+// FileCheckpoint is a Checkpointer keeping the block number in a file.
+type FileCheckpoint string
+
+func (f FileCheckpoint) Load(ctx context.Context) (uint32, error) {
+	cnt, err := ioutil.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	num, err := strconv.ParseUint(strings.TrimSpace(string(cnt)), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("reading checkpoint %s: %w", f, err)
+	}
+	return uint32(num), nil
+}
+
+// Save writes the block number to a temporary file first, so a crash
+// never leaves a truncated checkpoint.
+func (f FileCheckpoint) Save(ctx context.Context, blockNum uint32) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(string(f)), filepath.Base(string(f))+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(strconv.FormatUint(uint64(blockNum), 10)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
+
+// BlockFollower reads blocks in order, from a start block up to the
+// head (or last irreversible) block, then keeps following it.  Blocks
+// are fetched one at a time, only as fast as they are consumed.
+//
+// Following the head, a block emitted can later be replaced by a
+// fork.  Follow the last irreversible block when that matters.
+type BlockFollower struct {
+	// Irreversible makes the follower stop at the last irreversible
+	// block instead of the head block.
+	Irreversible bool
+
+	// PollInterval is the delay between `get_info` calls when caught
+	// up, defaults to 500ms.
+	PollInterval time.Duration
+
+	// Checkpoint, when set, is saved after every block handled, and
+	// loaded when starting: the follower resumes after the block
+	// saved instead of at the start block.
+	Checkpoint Checkpointer
+
+	api   *API
+	start uint32
+	err   error
+}
+
+// FollowBlocks returns a follower starting at block `start`.  Zero
+// starts at the current head (or last irreversible) block.
+func (api *API) FollowBlocks(start uint32) *BlockFollower {
+	return &BlockFollower{
+		api:   api,
+		start: start,
+	}
+}
+
+// Run calls `handler` with each block, in order, until `ctx` is done
+// or an error occurs.  The checkpoint is saved once the handler
+// returns successfully. Transient API errors are retried.
+func (f *BlockFollower) Run(ctx context.Context, handler func(block *BlockResp) error) error {
+	next, err := f.startBlock(ctx)
+	if err != nil {
+		return err
+	}
+
+	interval := f.PollInterval
+	if interval == 0 {
+		interval = 500 * time.Millisecond
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		target, err := f.target(ctx)
+		if err != nil && !IsRetryableError(err) {
+			return f.ctxErr(ctx, err)
+		}
+		if next == 0 && err == nil {
+			next = target
+		}
+
+		for ; err == nil && next != 0 && next <= target; next++ {
+			var block *BlockResp
+			block, err = f.api.GetBlockByNumContext(ctx, next)
+			if err != nil {
+				if !IsRetryableError(err) {
+					return f.ctxErr(ctx, err)
+				}
+				break
+			}
+
+			if err := handler(block); err != nil {
+				return err
+			}
+
+			if f.Checkpoint != nil {
+				if err := f.Checkpoint.Save(ctx, next); err != nil {
+					return fmt.Errorf("saving checkpoint: %w", err)
+				}
+			}
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// Blocks runs the follower in the background, sending blocks on the
+// returned channel.  The channel is closed when `ctx` is done or on
+// error (see Err). With a Checkpoint, a block is saved once received.
+func (f *BlockFollower) Blocks(ctx context.Context) <-chan *BlockResp {
+	out := make(chan *BlockResp)
+	go func() {
+		defer close(out)
+		err := f.Run(ctx, func(block *BlockResp) error {
+			select {
+			case out <- block:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			f.err = err
+		}
+	}()
+	return out
+}
+
+// Err returns the error that stopped Blocks, if any. Read it once the
+// channel is closed.
+func (f *BlockFollower) Err() error {
+	return f.err
+}
+
+// ctxErr reports the cancellation of `ctx` rather than the failure
+// of the request it interrupted.
+func (f *BlockFollower) ctxErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func (f *BlockFollower) startBlock(ctx context.Context) (uint32, error) {
+	if f.Checkpoint != nil {
+		saved, err := f.Checkpoint.Load(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("loading checkpoint: %w", err)
+		}
+		if saved != 0 {
+			return saved + 1, nil
+		}
+	}
+	return f.start, nil
+}
+
+func (f *BlockFollower) target(ctx context.Context) (uint32, error) {
+	info, err := f.api.GetInfoContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if f.Irreversible {
+		return info.LastIrreversibleBlockNum, nil
+	}
+	return info.HeadBlockNum, nil
+}
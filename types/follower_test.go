@@ -0,0 +1,112 @@
+package types_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBlocks serves a chain growing by one block on every
+// `get_info`, up to `maxHead`, with the last irreversible block 3
+// blocks behind.
+func newTestBlocks(t *testing.T, maxHead uint32) *httptest.Server {
+	var lock sync.Mutex
+	head := uint32(5)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		switch r.URL.Path {
+		case "/v1/chain/get_info":
+			if head < maxHead {
+				head++
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"head_block_num": head, "last_irreversible_block_num": head - 3})
+		case "/v1/chain/get_block":
+			var params map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+			var num uint32
+			fmt.Sscanf(params["block_num_or_id"], "%d", &num)
+			if num > head {
+				t.Errorf("block %d requested beyond head %d", num, head)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": testBlockID(num, 0), "block_num": num, "timestamp": "2018-06-01T12:00:00"})
+		}
+	}))
+}
+
+func collectBlocks(t *testing.T, follower *types.BlockFollower, count int) []uint32 {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var nums []uint32
+	err := follower.Run(ctx, func(block *types.BlockResp) error {
+		nums = append(nums, block.BlockNum)
+		if len(nums) == count {
+			cancel()
+		}
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	return nums
+}
+
+func TestBlockFollower(t *testing.T) {
+	server := newTestBlocks(t, 20)
+	defer server.Close()
+
+	api := types.New(server.URL)
+
+	follower := api.FollowBlocks(2)
+	follower.PollInterval = time.Millisecond
+	assert.Equal(t, []uint32{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, collectBlocks(t, follower, 10))
+
+	// The chain doesn't go beyond 20, so irreversible blocks stop at 17.
+	irreversible := api.FollowBlocks(15)
+	irreversible.Irreversible = true
+	irreversible.PollInterval = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	var nums []uint32
+	for block := range irreversible.Blocks(ctx) {
+		nums = append(nums, block.BlockNum)
+	}
+	require.NoError(t, irreversible.Err())
+	assert.Equal(t, []uint32{15, 16, 17}, nums)
+}
+
+func TestBlockFollower_Checkpoint(t *testing.T) {
+	server := newTestBlocks(t, 100)
+	defer server.Close()
+
+	api := types.New(server.URL)
+	checkpoint := types.FileCheckpoint(filepath.Join(t.TempDir(), "checkpoint"))
+
+	follower := api.FollowBlocks(3)
+	follower.PollInterval = time.Millisecond
+	follower.Checkpoint = checkpoint
+	assert.Equal(t, []uint32{3, 4, 5}, collectBlocks(t, follower, 3))
+
+	saved, err := checkpoint.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(5), saved)
+
+	// Restarting resumes after the checkpoint, not at the start block.
+	follower = api.FollowBlocks(3)
+	follower.PollInterval = time.Millisecond
+	follower.Checkpoint = checkpoint
+	assert.Equal(t, []uint32{6, 7, 8}, collectBlocks(t, follower, 3))
+}
@@ -0,0 +1,173 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type StepType int
+
+const (
+	StepNew          StepType = iota // block added on top of the chain
+	StepUndo                         // block removed from the chain by a fork, undo what was done with it
+	StepIrreversible                 // block became irreversible, it will never be undone
+)
+
+func (s StepType) String() string {
+	switch s {
+	case StepNew:
+		return "new"
+	case StepUndo:
+		return "undo"
+	case StepIrreversible:
+		return "irreversible"
+	}
+	return "unknown"
+}
+
+// BlockStep is a change of the chain seen by a ForkableBlockSource.
+type BlockStep struct {
+	Type  StepType
+	Block *BlockResp
+}
+
+// ErrIrreversibleFork is returned when a block links to none of the
+// blocks kept, the fork going further back than the last irreversible
+// block.
+var ErrIrreversibleFork = errors.New("fork below the last irreversible block")
+
+// ForkableBlockSource follows the head of the chain, and tells about
+// forks.  It keeps the reversible segment of the chain (the blocks
+// emitted but not irreversible yet), and checks that each new block
+// links to it through `Previous`.  When a fork is detected, blocks
+// of the old branch are undone, most recent first, before the blocks
+// of the new branch are emitted:
+//
+//	New 5, New 6, New 7, Undo 7, Undo 6, New 6', New 7', Irreversible 5...
+type ForkableBlockSource struct {
+	// PollInterval is the delay between `get_info` calls when caught
+	// up, defaults to 500ms.
+	PollInterval time.Duration
+
+	api     *API
+	start   uint32
+	segment []*BlockResp // reversible blocks, in order
+	lastIrr *BlockResp   // last block emitted as irreversible
+	nextNum uint32
+	started bool
+}
+
+// ForkableBlocks returns a source starting at block `start`, or after
+// the last irreversible block if zero.
+func (api *API) ForkableBlocks(start uint32) *ForkableBlockSource {
+	return &ForkableBlockSource{
+		api:   api,
+		start: start,
+	}
+}
+
+// Run calls `handler` with each step, until `ctx` is done or an error
+// occurs.  Transient API errors are retried.
+func (s *ForkableBlockSource) Run(ctx context.Context, handler func(step BlockStep) error) error {
+	interval := s.PollInterval
+	if interval == 0 {
+		interval = 500 * time.Millisecond
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := s.poll(ctx, handler)
+		if err != nil && !IsRetryableError(err) {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *ForkableBlockSource) poll(ctx context.Context, handler func(step BlockStep) error) error {
+	info, err := s.api.GetInfoContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !s.started {
+		s.nextNum = s.start
+		if s.nextNum == 0 {
+			s.nextNum = info.LastIrreversibleBlockNum + 1
+		}
+		s.started = true
+	}
+
+	for s.nextNum <= info.HeadBlockNum {
+		block, err := s.api.GetBlockByNumContext(ctx, s.nextNum)
+		if err != nil {
+			return err
+		}
+		id, err := blockRespID(block)
+		if err != nil {
+			return err
+		}
+		block.ID = id
+
+		tail := s.tail()
+		if tail == nil || bytes.Equal(block.Previous, tail.ID) {
+			s.segment = append(s.segment, block)
+			s.nextNum++
+			if err := handler(BlockStep{Type: StepNew, Block: block}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// `block` is on another branch: undo our tip, and check the
+		// block at its height on the next round.
+		if len(s.segment) == 0 {
+			return fmt.Errorf("block %d doesn't link to irreversible block %d: %w", block.BlockNum, tail.BlockNum, ErrIrreversibleFork)
+		}
+		s.segment = s.segment[:len(s.segment)-1]
+		s.nextNum = tail.BlockNum
+		if err := handler(BlockStep{Type: StepUndo, Block: tail}); err != nil {
+			return err
+		}
+	}
+
+	for len(s.segment) > 0 && s.segment[0].BlockNum <= info.LastIrreversibleBlockNum {
+		block := s.segment[0]
+		s.segment = s.segment[1:]
+		s.lastIrr = block
+		if err := handler(BlockStep{Type: StepIrreversible, Block: block}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tail returns the block new blocks should link to.
+func (s *ForkableBlockSource) tail() *BlockResp {
+	if len(s.segment) > 0 {
+		return s.segment[len(s.segment)-1]
+	}
+	return s.lastIrr
+}
+
+// blockRespID returns the ID sent by the node, computing it from the
+// header if missing.
+func blockRespID(block *BlockResp) (SHA256Bytes, error) {
+	if len(block.ID) != 0 {
+		return block.ID, nil
+	}
+	return block.BlockID()
+}
@@ -0,0 +1,98 @@
+package types_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testForkingChain serves the blocks of `forks[n]`, where `n` is the
+// number of `get_info` calls so far: each entry is the fork of every
+// block, by number.  Blocks link to the block of the same fork before
+// them, or of fork 0.
+type testForkingChain struct {
+	lock  sync.Mutex
+	calls int
+	heads []uint32
+	libs  []uint32
+	forks []map[uint32]byte
+}
+
+func (c *testForkingChain) fork(num uint32) byte {
+	return c.forks[c.calls-1][num]
+}
+
+func (c *testForkingChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	switch r.URL.Path {
+	case "/v1/chain/get_info":
+		if c.calls < len(c.heads) {
+			c.calls++
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"head_block_num": c.heads[c.calls-1], "last_irreversible_block_num": c.libs[c.calls-1]})
+	case "/v1/chain/get_block":
+		var params map[string]string
+		json.NewDecoder(r.Body).Decode(&params)
+		var num uint32
+		fmt.Sscanf(params["block_num_or_id"], "%d", &num)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":        testBlockID(num, c.fork(num)),
+			"previous":  testBlockID(num-1, c.fork(num-1)),
+			"block_num": num,
+			"timestamp": "2018-06-01T12:00:00",
+		})
+	}
+}
+
+func TestForkableBlockSource(t *testing.T) {
+	chain := &testForkingChain{
+		heads: []uint32{4, 6, 7, 8},
+		libs:  []uint32{1, 2, 3, 5},
+		forks: []map[uint32]byte{
+			{},
+			{},
+			{5: 1, 6: 1, 7: 1}, // 5 and 6 replaced
+			{5: 1, 6: 1, 7: 1},
+		},
+	}
+	server := httptest.NewServer(chain)
+	defer server.Close()
+
+	source := types.New(server.URL).ForkableBlocks(2)
+	source.PollInterval = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var steps []string
+	err := source.Run(ctx, func(step types.BlockStep) error {
+		steps = append(steps, fmt.Sprintf("%s %d/%d", step.Type, step.Block.BlockNum, step.Block.ID[31]))
+		if step.Type == types.StepIrreversible && step.Block.BlockNum == 5 {
+			cancel()
+		}
+		return nil
+	})
+	require.Equal(t, context.Canceled, err)
+
+	assert.Equal(t, []string{
+		"new 2/0", "new 3/0", "new 4/0", "new 5/0", "new 6/0",
+		"irreversible 2/0",
+		"undo 6/0", "undo 5/0", // 7/1 doesn't link to 6/0
+		"new 5/1", "new 6/1", "new 7/1",
+		"irreversible 3/0",
+		"new 8/0",
+		"irreversible 4/0", "irreversible 5/1",
+	}, steps)
+}
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"context"
+	"fmt"
+)
+
+// ActionsIterator walks through the action history of an account,
+// one action at a time, fetching pages from `get_actions` as needed:
+//
+//	it := api.IterateActions(ctx, "eosio.token", true)
+//	for it.Next() {
+//		if transfer, ok := it.Action().Trace.Action.Data.(*token.Transfer); ok {
+//			...
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type ActionsIterator struct {
+	// PageSize is the number of actions requested at once, defaults to
+	// 100.
+	PageSize int64
+
+	// Pos is the account sequence of the first action returned. It
+	// defaults to 0 going forward, and to -1 (the last action) going
+	// backward.  Change it before the first call to Next.
+	Pos int64
+
+	api      *API
+	ctx      context.Context
+	account  AccountName
+	backward bool
+
+	page    []ActionResp
+	current *ActionResp
+	done    bool
+	err     error
+}
+
+// IterateActions returns an iterator over the actions of `account`,
+// from the oldest if `backward` is false, or from the most recent.
+func (api *API) IterateActions(ctx context.Context, account AccountName, backward bool) *ActionsIterator {
+	it := &ActionsIterator{
+		PageSize: 100,
+		api:      api,
+		ctx:      ctx,
+		account:  account,
+		backward: backward,
+	}
+	if backward {
+		it.Pos = -1
+	}
+	return it
+}
+
+// Next moves to the next action. It returns false when the history is
+// exhausted, or on error (check Err).
+func (it *ActionsIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if len(it.page) == 0 {
+		if it.done || !it.fetch() {
+			it.current = nil
+			return false
+		}
+	}
+
+	it.current = &it.page[0]
+	it.page = it.page[1:]
+	return true
+}
+
+// Action returns the current action.
+func (it *ActionsIterator) Action() *ActionResp {
+	return it.current
+}
+
+func (it *ActionsIterator) Err() error {
+	return it.err
+}
+
+// fetch loads the next page, skipping any action already returned.
+func (it *ActionsIterator) fetch() bool {
+	pageSize := it.PageSize
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+
+	params := GetActionsRequest{AccountName: it.account, Pos: it.Pos, Offset: pageSize - 1}
+	if it.backward {
+		params.Offset = -(pageSize - 1)
+	}
+
+	resp, err := it.api.GetActionsContext(it.ctx, params)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	var actions []ActionResp
+	for _, action := range resp.Actions {
+		if it.backward && it.Pos != -1 && action.AccountSeq > it.Pos {
+			continue
+		}
+		if !it.backward && action.AccountSeq < it.Pos {
+			continue
+		}
+		actions = append(actions, action)
+	}
+
+	if len(actions) == 0 {
+		it.done = true
+		return false
+	}
+
+	first, last := actions[0].AccountSeq, actions[len(actions)-1].AccountSeq
+	if it.backward {
+		for i, j := 0, len(actions)-1; i < j; i, j = i+1, j-1 {
+			actions[i], actions[j] = actions[j], actions[i]
+		}
+		if first == 0 {
+			it.done = true
+		}
+		it.Pos = first - 1
+	} else {
+		it.Pos = last + 1
+	}
+
+	it.page = actions
+	return true
+}
+
+// mapToRegisteredActions decodes the data of the action, and of its
+// inline actions, into their registered types.  Data that doesn't
+// decode is left as is, with the error in DecodeErr, so a malformed
+// action doesn't hide the rest of the history.
+func (t *TransactionTrace) mapToRegisteredActions() {
+	if t.Action != nil {
+		if err := t.Action.MapToRegisteredAction(); err != nil {
+			t.DecodeErr = fmt.Errorf("action %s::%s: %w", t.Action.Account, t.Action.Name, err)
+		}
+	}
+
+	for _, inline := range t.InlineTraces {
+		inline.mapToRegisteredActions()
+	}
+}
@@ -0,0 +1,123 @@
+package types_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Akagi201/eosgo/token"
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHistory serves `get_actions` over `count` transfers, with
+// the range semantics of the `history_plugin`.
+func newTestHistory(t *testing.T, count int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/history/get_actions", r.URL.Path)
+
+		var req types.GetActionsRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		pos := req.Pos
+		if pos == -1 {
+			pos = count
+		}
+		start, end := pos, pos+req.Offset
+		if req.Offset < 0 {
+			start, end = pos+req.Offset, pos
+		}
+
+		resp := map[string]interface{}{"last_irreversible_block": 100}
+		var actions []interface{}
+		for seq := start; seq <= end; seq++ {
+			if seq < 0 || seq >= count {
+				continue
+			}
+			actions = append(actions, map[string]interface{}{
+				"global_action_seq":  1000 + seq,
+				"account_action_seq": seq,
+				"block_num":          10 + seq,
+				"block_time":         "2018-06-11T17:04:00.500",
+				"action_trace": map[string]interface{}{
+					"act": map[string]interface{}{
+						"account":       "eosio.token",
+						"name":          "transfer",
+						"authorization": []interface{}{map[string]string{"actor": "alice", "permission": "active"}},
+						"data":          map[string]interface{}{"from": "alice", "to": "bob", "quantity": "1.0000 EOS", "memo": fmt.Sprintf("%d", seq)},
+					},
+				},
+			})
+		}
+		resp["actions"] = actions
+
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestActionsIterator(t *testing.T) {
+	server := newTestHistory(t, 7)
+	defer server.Close()
+
+	api := types.New(server.URL)
+
+	for _, backward := range []bool{false, true} {
+		it := api.IterateActions(context.Background(), "alice", backward)
+		it.PageSize = 3
+
+		var memos []string
+		for it.Next() {
+			transfer, ok := it.Action().Trace.Action.Data.(*token.Transfer)
+			require.True(t, ok, "action data should be a *token.Transfer, got %T", it.Action().Trace.Action.Data)
+			assert.Equal(t, types.AccountName("bob"), transfer.To)
+			memos = append(memos, transfer.Memo)
+		}
+		require.NoError(t, it.Err())
+
+		if backward {
+			assert.Equal(t, []string{"6", "5", "4", "3", "2", "1", "0"}, memos)
+		} else {
+			assert.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6"}, memos)
+		}
+	}
+}
+
+func TestAPI_GetActions(t *testing.T) {
+	server := newTestHistory(t, 7)
+	defer server.Close()
+
+	resp, err := types.New(server.URL).GetActions(types.GetActionsRequest{AccountName: "alice", Pos: 2, Offset: 1})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Actions, 2)
+	assert.Equal(t, int64(2), resp.Actions[0].AccountSeq)
+	assert.Equal(t, uint32(12), resp.Actions[0].BlockNum)
+	assert.Equal(t, 2018, resp.Actions[0].BlockTime.Year())
+	assert.Equal(t, uint32(100), resp.LastIrreversibleBlock)
+}
+
+func TestAPI_GetActions_MalformedAction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"actions": [
+			{"account_action_seq": 0, "action_trace": {"act": {"account": "eosio.token", "name": "transfer", "data": {"from": "alice", "to": "bob", "quantity": "1.0000 EOS", "memo": 42}, "hex_data": "00"}}},
+			{"account_action_seq": 1, "action_trace": {"act": {"account": "eosio.token", "name": "transfer", "data": {"from": "alice", "to": "bob", "quantity": "1.0000 EOS", "memo": "ok"}}}}
+		]}`)
+	}))
+	defer server.Close()
+
+	resp, err := types.New(server.URL).GetActions(types.GetActionsRequest{AccountName: "alice"})
+	require.NoError(t, err)
+	require.Len(t, resp.Actions, 2)
+
+	malformed := resp.Actions[0].Trace
+	assert.Error(t, malformed.DecodeErr)
+	assert.Equal(t, "42", fmt.Sprint(malformed.Action.Data.(map[string]interface{})["memo"]), "data kept as received")
+	assert.Equal(t, []byte{0}, []byte(malformed.Action.HexData))
+
+	assert.NoError(t, resp.Actions[1].Trace.DecodeErr)
+	assert.IsType(t, &token.Transfer{}, resp.Actions[1].Trace.Action.Data)
+}
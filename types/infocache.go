@@ -0,0 +1,106 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// InfoCachePolicy decides how the `get_info` answers used by
+// TxOptions.FillFromChain are reused, instead of calling the node
+// for every transaction.  Set it on `API.InfoCache`.
+type InfoCachePolicy struct {
+	// TTL is how long an answer is reused.  Defaults to 1 second.
+	// Negative values disable the cache.
+	TTL time.Duration
+
+	// RefreshInterval is how often RunInfoRefresher calls `get_info`.
+	// Defaults to half the TTL.
+	RefreshInterval time.Duration
+}
+
+func (p *InfoCachePolicy) ttl() time.Duration {
+	if p == nil || p.TTL == 0 {
+		return time.Second
+	}
+	return p.TTL
+}
+
+func (p *InfoCachePolicy) refreshInterval() time.Duration {
+	if p != nil && p.RefreshInterval > 0 {
+		return p.RefreshInterval
+	}
+	if ttl := p.ttl(); ttl > 0 {
+		return ttl / 2
+	}
+	return 500 * time.Millisecond
+}
+
+// cachedGetInfo returns the last `get_info` answer while it's fresh,
+// along with the time it was fetched.
+func (api *API) cachedGetInfo(ctx context.Context) (*InfoResp, time.Time, error) {
+	api.lastGetInfoLock.Lock()
+	defer api.lastGetInfoLock.Unlock()
+
+	ttl := api.InfoCache.ttl()
+	if ttl > 0 && api.lastGetInfo != nil && time.Since(api.lastGetInfoStamp) < ttl {
+		return api.lastGetInfo, api.lastGetInfoStamp, nil
+	}
+
+	info, err := api.GetInfoContext(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	api.lastGetInfo, api.lastGetInfoStamp = info, time.Now()
+
+	return info, api.lastGetInfoStamp, nil
+}
+
+// nodeNow estimates the time of the node: the local clock, corrected
+// by the offset seen in a `get_info` answer, the last one known when
+// the node can't be reached.  It's the local clock when the node never
+// answered.
+func (api *API) nodeNow(ctx context.Context) time.Time {
+	info, fetchedAt, err := api.cachedGetInfo(ctx)
+	if err != nil {
+		api.lastGetInfoLock.Lock()
+		info, fetchedAt = api.lastGetInfo, api.lastGetInfoStamp
+		api.lastGetInfoLock.Unlock()
+	}
+
+	now := time.Now()
+	if info == nil {
+		return now
+	}
+	return now.Add(info.HeadBlockTime.Sub(fetchedAt))
+}
+
+// RunInfoRefresher calls `get_info` every `InfoCache.RefreshInterval`
+// until `ctx` is done, so transactions are filled from the cache
+// without waiting on the node:
+//
+//	api.InfoCache = &types.InfoCachePolicy{TTL: 2 * time.Second}
+//	go api.RunInfoRefresher(ctx)
+//
+// Failed calls are only logged (with `Debug`): the cached answer then
+// expires as usual.
+func (api *API) RunInfoRefresher(ctx context.Context) error {
+	ticker := time.NewTicker(api.InfoCache.refreshInterval())
+	defer ticker.Stop()
+
+	for {
+		info, err := api.GetInfoContext(ctx)
+		if err == nil {
+			api.lastGetInfoLock.Lock()
+			api.lastGetInfo, api.lastGetInfoStamp = info, time.Now()
+			api.lastGetInfoLock.Unlock()
+		} else if api.Debug && ctx.Err() == nil {
+			api.logger().Debug("refreshing chain info failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
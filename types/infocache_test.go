@@ -0,0 +1,98 @@
+package types_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newInfoServer serves a chain at block 100, irreversible at 80,
+// whose head block is an hour old.
+func newInfoServer(t *testing.T, getInfoCalls *int32) *httptest.Server {
+	headTime := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/chain/get_info":
+			atomic.AddInt32(getInfoCalls, 1)
+			fmt.Fprintf(w, `{"chain_id":"aca376f206b8fc25a6ed44dbdc66547c36c6c33e3a119ffbeaef943642f0e906","head_block_num":100,"head_block_id":"00000064aaaaaaaa1111111111111111aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","head_block_time":%q,"last_irreversible_block_num":80,"last_irreversible_block_id":"00000050bbbbbbbb2222222222222222bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"}`, headTime.Format("2006-01-02T15:04:05"))
+		case "/v1/chain/get_block":
+			var params struct {
+				BlockNumOrID string `json:"block_num_or_id"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+			assert.Equal(t, "90", params.BlockNumOrID)
+			w.Write([]byte(`{"id":"0000005acccccccc3333333333333333cccccccccccccccccccccccccccccccc","block_num":90,"timestamp":"2020-01-20T08:00:05.000"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTxOptions_FillFromChain(t *testing.T) {
+	var calls int32
+	api := types.New(newInfoServer(t, &calls).URL)
+
+	tests := []struct {
+		name       string
+		opts       types.TxOptions
+		refBlock   uint16
+		expiration time.Duration
+	}{
+		{"default", types.TxOptions{}, 100, 30 * time.Second},
+		{"irreversible", types.TxOptions{RefBlock: types.RefBlockIrreversible, Expiration: time.Minute}, 80, time.Minute},
+		{"behind head", types.TxOptions{RefBlocksBehind: 10}, 90, 30 * time.Second},
+		{"node time", types.TxOptions{UseNodeTime: true}, 100, 30*time.Second - time.Hour},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := test.opts
+			require.NoError(t, opts.FillFromChain(api))
+
+			tx := types.NewTransaction(nil, &opts)
+			assert.Equal(t, test.refBlock, tx.RefBlockNum)
+			assert.WithinDuration(t, time.Now().Add(test.expiration), tx.Expiration.Time, 2*time.Second)
+		})
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "get_info answers are cached for a second")
+}
+
+func TestAPI_InfoCache(t *testing.T) {
+	var calls int32
+	api := types.New(newInfoServer(t, &calls).URL)
+	api.InfoCache = &types.InfoCachePolicy{TTL: -1}
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, (&types.TxOptions{}).FillFromChain(api))
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	api.InfoCache = &types.InfoCachePolicy{TTL: time.Hour, RefreshInterval: 10 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- api.RunInfoRefresher(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) >= 6
+	}, 5*time.Second, 5*time.Millisecond)
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+
+	refreshed := atomic.LoadInt32(&calls)
+	require.NoError(t, (&types.TxOptions{}).FillFromChain(api))
+	assert.Equal(t, refreshed, atomic.LoadInt32(&calls), "filled from the refreshed cache")
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// CallInfo describes a single HTTP round-trip made by the API. With
+// a Pool or a RetryPolicy, one API call can make several of them.
+type CallInfo struct {
+	BaseAPI  string // "chain", "wallet", "history"...
+	Endpoint string // "get_info", "push_transaction"...
+	URL      string
+
+	Request    []byte // JSON body sent, nil if none
+	Response   []byte // raw body received, set for `After`
+	StatusCode int    // set for `After`, 0 if no answer was received
+	Duration   time.Duration
+	Err        error
+
+	// Sensitive is set for wallet calls carrying private keys or
+	// passwords, in the request or in the response.  Use
+	// RedactedRequest and RedactedResponse before logging them.
+	Sensitive bool
+}
+
+var redacted = []byte(`"<redacted>"`)
+
+func (c *CallInfo) RedactedRequest() []byte {
+	if c.Sensitive && c.Request != nil {
+		return redacted
+	}
+	return c.Request
+}
+
+func (c *CallInfo) RedactedResponse() []byte {
+	if c.Sensitive && c.Response != nil {
+		return redacted
+	}
+	return c.Response
+}
+
+// Interceptor observes the calls made by an API. `Before` is called
+// before sending the request, `After` once the response is read or the
+// call failed.  Interceptors are called in the order they were added
+// for `Before`, and in reverse order for `After`.
+type Interceptor interface {
+	Before(ctx context.Context, call *CallInfo)
+	After(ctx context.Context, call *CallInfo)
+}
+
+// InterceptorFuncs builds an Interceptor from functions, any of them
+// can be nil.
+type InterceptorFuncs struct {
+	BeforeFunc func(ctx context.Context, call *CallInfo)
+	AfterFunc  func(ctx context.Context, call *CallInfo)
+}
+
+func (i InterceptorFuncs) Before(ctx context.Context, call *CallInfo) {
+	if i.BeforeFunc != nil {
+		i.BeforeFunc(ctx, call)
+	}
+}
+
+func (i InterceptorFuncs) After(ctx context.Context, call *CallInfo) {
+	if i.AfterFunc != nil {
+		i.AfterFunc(ctx, call)
+	}
+}
+
+// Use adds interceptors to the API.
+func (api *API) Use(interceptors ...Interceptor) {
+	api.Interceptors = append(api.Interceptors, interceptors...)
+}
+
+func (api *API) interceptBefore(ctx context.Context, call *CallInfo) {
+	for _, i := range api.Interceptors {
+		i.Before(ctx, call)
+	}
+}
+
+func (api *API) interceptAfter(ctx context.Context, call *CallInfo) {
+	for idx := len(api.Interceptors) - 1; idx >= 0; idx-- {
+		api.Interceptors[idx].After(ctx, call)
+	}
+}
+
+// IsSensitiveCall tells whether a call carries secrets: private keys
+// and wallet passwords.
+func IsSensitiveCall(baseAPI, endpoint string) bool {
+	if baseAPI != "wallet" {
+		return false
+	}
+
+	switch endpoint {
+	case "import_key", "list_keys", "create", "unlock", "remove_key":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Logger receives the tracing output of the API (when `API.Debug` is
+// set), of the Encoder and of the Decoder (when `Debug` is set).
+// Key/value pairs follow the message, so it plugs easily into
+// structured loggers.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+}
+
+// LoggerFunc adapts a function to the Logger interface.
+type LoggerFunc func(msg string, keyvals ...interface{})
+
+func (f LoggerFunc) Debug(msg string, keyvals ...interface{}) {
+	f(msg, keyvals...)
+}
+
+// stdLogger writes to the standard `log` package, so to stderr
+// unless configured otherwise.
+type stdLogger struct{}
+
+func (stdLogger) Debug(msg string, keyvals ...interface{}) {
+	if len(keyvals) == 0 {
+		log.Println(msg)
+		return
+	}
+
+	var fields []string
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 < len(keyvals) {
+			fields = append(fields, fmt.Sprintf("%v=%v", keyvals[i], keyvals[i+1]))
+		} else {
+			fields = append(fields, fmt.Sprintf("%v", keyvals[i]))
+		}
+	}
+	log.Println(msg, strings.Join(fields, " "))
+}
+
+var (
+	packageLogger     Logger = stdLogger{}
+	packageLoggerLock sync.RWMutex
+)
+
+// SetLogger replaces the package Logger, used by the Encoder, the
+// Decoder and by any API without its own `Logger`.  Pass nil to get
+// back to the default, which writes through the standard `log`
+// package.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = stdLogger{}
+	}
+
+	packageLoggerLock.Lock()
+	defer packageLoggerLock.Unlock()
+	packageLogger = l
+}
+
+func getLogger() Logger {
+	packageLoggerLock.RLock()
+	defer packageLoggerLock.RUnlock()
+	return packageLogger
+}
+
+func (api *API) logger() Logger {
+	if api.Logger != nil {
+		return api.Logger
+	}
+	return getLogger()
+}
@@ -114,7 +114,7 @@ func ReadP2PMessageData(r io.Reader) (envelope *P2PMessageEnvelope, err error) {
 	}
 
 	if err != nil {
-		fmt.Println("ReadFull, error: ", err)
+		println("ReadFull, error: ", err)
 		return
 	}
 
@@ -125,7 +125,7 @@ func ReadP2PMessageData(r io.Reader) (envelope *P2PMessageEnvelope, err error) {
 	decoder.DecodeActions(false)
 	err = decoder.Decode(envelope)
 	if err != nil {
-		fmt.Println("Failing data: ", hex.EncodeToString(data))
+		println("Failing data: ", hex.EncodeToString(data))
 	}
 	envelope.Raw = data
 	return
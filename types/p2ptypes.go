@@ -269,8 +269,25 @@ func (t *TransactionWithID) UnmarshalJSON(data []byte) error {
 		if err := json.Unmarshal(data, &packed); err != nil {
 			return err
 		}
+
+		// `get_block` adds the `id` next to the packed transaction.
+		var withID struct {
+			ID SHA256Bytes `json:"id"`
+		}
+		if err := json.Unmarshal(data, &withID); err != nil {
+			return err
+		}
+
+		id := withID.ID
+		if len(id) == 0 {
+			var err error
+			if id, err = packed.TransactionID(); err != nil {
+				return err
+			}
+		}
+
 		*t = TransactionWithID{
-			ID:     packed.ID(),
+			ID:     id,
 			Packed: &packed,
 		}
 
@@ -310,8 +327,13 @@ func (t *TransactionWithID) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	id, err := packed.TransactionID()
+	if err != nil {
+		return err
+	}
+
 	*t = TransactionWithID{
-		ID:     packed.ID(),
+		ID:     id,
 		Packed: &packed,
 	}
 
@@ -0,0 +1,358 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNoHealthyEndpoint is returned by calls on a Pool when no
+// endpoint can be used, either because they all failed their health
+// check or because they are on another chain.
+var ErrNoHealthyEndpoint = errors.New("no healthy endpoint in pool")
+
+// Pool holds several `nodeos` endpoints serving the same chain. Reads
+// and writes are routed to the healthiest endpoint.  Reads fail over
+// to the next one on transport errors.  Writes don't, as the first
+// node may have accepted them: see RetryPolicy and SubmitTransaction
+// to resubmit transactions safely.
+//
+// Endpoints are health checked with `get_info` every
+// HealthCheckInterval: the latency, the failures and how far behind
+// the best known head block they are is used to rank them. Endpoints
+// reporting another `chain_id` than ChainID are never used.
+type Pool struct {
+	ChainID   SHA256Bytes // If nil, endpoints are not checked against a chain ID
+	Endpoints []*Endpoint
+
+	HealthCheckInterval time.Duration // defaults to 30 seconds
+	MaxHeadLag          uint32        // in blocks, endpoints further behind the best head are used last. Defaults to 10 (5 seconds)
+
+	checkLock sync.Mutex
+	lastCheck time.Time
+}
+
+// Endpoint is one node of a Pool.  It has its own HttpClient so
+// FixKeepAlives and EnableKeepAlives are tracked per node.
+type Endpoint struct {
+	BaseURL    string
+	HttpClient *http.Client
+
+	lock         sync.Mutex
+	chainID      SHA256Bytes
+	headBlockNum uint32
+	lastCheck    time.Time
+	latency      time.Duration
+	failures     int
+	lastFailure  time.Time
+}
+
+// EndpointStats is a snapshot of the health of an Endpoint.
+type EndpointStats struct {
+	BaseURL      string
+	ChainID      SHA256Bytes
+	WrongChain   bool
+	HeadBlockNum uint32
+	HeadLag      uint32
+	Latency      time.Duration // moving average of successful calls
+	Failures     int           // consecutive failures
+	LastCheck    time.Time
+}
+
+// NewPool creates an API that spreads calls over `baseURLs`, all
+// expected to be on the `chainID` chain.  `BaseURL` is set to the
+// first endpoint, for information.
+func NewPool(chainID SHA256Bytes, baseURLs ...string) *API {
+	pool := &Pool{ChainID: chainID}
+	for _, baseURL := range baseURLs {
+		pool.Endpoints = append(pool.Endpoints, NewEndpoint(baseURL))
+	}
+
+	var baseURL string
+	if len(baseURLs) > 0 {
+		baseURL = baseURLs[0]
+	}
+
+	api := New(baseURL)
+	api.Pool = pool
+
+	return api
+}
+
+func NewEndpoint(baseURL string) *Endpoint {
+	return &Endpoint{
+		BaseURL:    baseURL,
+		HttpClient: newHTTPClient(),
+	}
+}
+
+// Stats returns the health of every endpoint, in the order they were
+// added.
+func (p *Pool) Stats() (out []EndpointStats) {
+	best := p.bestHead()
+	for _, ep := range p.Endpoints {
+		out = append(out, ep.stats(p.ChainID, best))
+	}
+	return
+}
+
+func (p *Pool) healthCheckInterval() time.Duration {
+	if p.HealthCheckInterval == 0 {
+		return 30 * time.Second
+	}
+	return p.HealthCheckInterval
+}
+
+func (p *Pool) maxHeadLag() uint32 {
+	if p.MaxHeadLag == 0 {
+		return 10
+	}
+	return p.MaxHeadLag
+}
+
+func (p *Pool) bestHead() (best uint32) {
+	for _, ep := range p.Endpoints {
+		ep.lock.Lock()
+		if p.isRightChain(ep.chainID) && ep.headBlockNum > best {
+			best = ep.headBlockNum
+		}
+		ep.lock.Unlock()
+	}
+	return
+}
+
+func (p *Pool) isRightChain(chainID SHA256Bytes) bool {
+	if p.ChainID == nil {
+		return true
+	}
+	return chainID != nil && bytes.Equal(p.ChainID, chainID)
+}
+
+// candidates returns the usable endpoints, healthiest first.
+func (p *Pool) candidates() []*Endpoint {
+	now := time.Now()
+	best := p.bestHead()
+
+	type candidate struct {
+		ep     *Endpoint
+		stats  EndpointStats
+		backed bool // recently failed, still in its backoff window
+		lagged bool
+	}
+
+	var cands []candidate
+	for _, ep := range p.Endpoints {
+		stats := ep.stats(p.ChainID, best)
+		if !p.isRightChain(stats.ChainID) {
+			continue // wrong chain, or not verified yet
+		}
+
+		ep.lock.Lock()
+		backed := ep.failures > 0 && now.Before(ep.lastFailure.Add(failureBackoff(ep.failures)))
+		ep.lock.Unlock()
+
+		cands = append(cands, candidate{
+			ep:     ep,
+			stats:  stats,
+			backed: backed,
+			lagged: stats.HeadLag > p.maxHeadLag(),
+		})
+	}
+
+	sort.SliceStable(cands, func(i, j int) bool {
+		a, b := cands[i], cands[j]
+		if a.backed != b.backed {
+			return !a.backed
+		}
+		if a.lagged != b.lagged {
+			return !a.lagged
+		}
+		if a.stats.Failures != b.stats.Failures {
+			return a.stats.Failures < b.stats.Failures
+		}
+		return a.stats.Latency < b.stats.Latency
+	})
+
+	out := make([]*Endpoint, len(cands))
+	for i, c := range cands {
+		out[i] = c.ep
+	}
+	return out
+}
+
+func failureBackoff(failures int) time.Duration {
+	if failures > 6 {
+		failures = 6
+	}
+	return time.Second << uint(failures-1) // 1s, 2s, 4s .. 32s
+}
+
+func (ep *Endpoint) stats(chainID SHA256Bytes, bestHead uint32) EndpointStats {
+	ep.lock.Lock()
+	defer ep.lock.Unlock()
+
+	stats := EndpointStats{
+		BaseURL:      ep.BaseURL,
+		ChainID:      ep.chainID,
+		HeadBlockNum: ep.headBlockNum,
+		Latency:      ep.latency,
+		Failures:     ep.failures,
+		LastCheck:    ep.lastCheck,
+	}
+	if chainID != nil && ep.chainID != nil {
+		stats.WrongChain = !bytes.Equal(chainID, ep.chainID)
+	}
+	if bestHead > ep.headBlockNum {
+		stats.HeadLag = bestHead - ep.headBlockNum
+	}
+	return stats
+}
+
+func (ep *Endpoint) recordSuccess(latency time.Duration) {
+	ep.lock.Lock()
+	defer ep.lock.Unlock()
+
+	if ep.latency == 0 {
+		ep.latency = latency
+	} else {
+		ep.latency = (ep.latency*4 + latency) / 5
+	}
+	ep.failures = 0
+}
+
+func (ep *Endpoint) recordFailure() {
+	ep.lock.Lock()
+	defer ep.lock.Unlock()
+
+	ep.failures++
+	ep.lastFailure = time.Now()
+}
+
+// CheckEndpoints runs `get_info` on every endpoint of the Pool, to
+// refresh their chain ID, head block and latency.  It is done
+// automatically every `HealthCheckInterval`, but can be forced.
+func (api *API) CheckEndpoints(ctx context.Context) error {
+	if api.Pool == nil {
+		return errors.New("no Pool configured")
+	}
+
+	api.Pool.checkLock.Lock()
+	defer api.Pool.checkLock.Unlock()
+
+	api.checkEndpoints(ctx)
+	return ctx.Err()
+}
+
+func (api *API) checkEndpoints(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, ep := range api.Pool.Endpoints {
+		wg.Add(1)
+		go func(ep *Endpoint) {
+			defer wg.Done()
+
+			var info *InfoResp
+			start := time.Now()
+			err := api.doRequest(ctx, ep.BaseURL, ep.HttpClient, "chain", "get_info", nil, &info)
+			if err != nil {
+				if ctx.Err() == nil {
+					ep.recordFailure()
+				}
+				return
+			}
+
+			ep.recordSuccess(time.Since(start))
+
+			ep.lock.Lock()
+			ep.chainID = info.ChainID
+			ep.headBlockNum = info.HeadBlockNum
+			ep.lastCheck = time.Now()
+			ep.lock.Unlock()
+		}(ep)
+	}
+	wg.Wait()
+
+	api.Pool.lastCheck = time.Now()
+}
+
+func (api *API) maybeCheckEndpoints(ctx context.Context, force bool) {
+	pool := api.Pool
+
+	pool.checkLock.Lock()
+	defer pool.checkLock.Unlock()
+
+	if force {
+		// Don't hammer the endpoints when they're all down.
+		if time.Since(pool.lastCheck) < time.Second {
+			return
+		}
+	} else if time.Since(pool.lastCheck) < pool.healthCheckInterval() {
+		return
+	}
+
+	api.checkEndpoints(ctx)
+}
+
+func (api *API) callPool(ctx context.Context, baseAPI string, endpoint string, body interface{}, out interface{}) error {
+	api.maybeCheckEndpoints(ctx, false)
+
+	candidates := api.Pool.candidates()
+	if len(candidates) == 0 {
+		api.maybeCheckEndpoints(ctx, true)
+		candidates = api.Pool.candidates()
+	}
+	if len(candidates) == 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return ErrNoHealthyEndpoint
+	}
+
+	var lastErr error
+	for _, ep := range candidates {
+		start := time.Now()
+		err := api.doRequest(ctx, ep.BaseURL, ep.HttpClient, baseAPI, endpoint, body, out)
+		if err == nil {
+			ep.recordSuccess(time.Since(start))
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if !isFailoverError(err) {
+			// The node answered, so it's alive.
+			ep.recordSuccess(time.Since(start))
+			return err
+		}
+
+		ep.recordFailure()
+		if !isReadCall(baseAPI, endpoint) {
+			return err
+		}
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+// isFailoverError tells whether a call should be tried on another
+// endpoint: the node didn't answer, or something in front of it
+// (a proxy) answered with a 5xx that isn't a `nodeos` error.
+func isFailoverError(err error) bool {
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= 500 && apiErr.ErrorStruct.Name == ""
+	}
+
+	return false
+}
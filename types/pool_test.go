@@ -0,0 +1,142 @@
+package types_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testChainID = "cf057bbfb72640471fd910bcb67639c22df9f92470936cddc1ade0e2f2e7dc4f"
+
+func newTestNode(t *testing.T, chainID string, headBlockNum uint32, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/chain/get_info":
+			fmt.Fprintf(w, `{"chain_id":%q,"head_block_num":%d}`, chainID, headBlockNum)
+		case "/v1/chain/get_account":
+			atomic.AddInt32(hits, 1)
+			fmt.Fprint(w, `{"account_name":"eosio"}`)
+		default:
+			t.Errorf("unexpected call to %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestPool_SkipsWrongChainAndDeadEndpoints(t *testing.T) {
+	var wrongHits, laggingHits, goodHits int32
+	wrong := newTestNode(t, "aca376f206b8fc25a6ed44dbdc66547c36c6c33e3a119ffbeaef943642f0e906", 1000, &wrongHits)
+	defer wrong.Close()
+	lagging := newTestNode(t, testChainID, 900, &laggingHits)
+	defer lagging.Close()
+	good := newTestNode(t, testChainID, 1000, &goodHits)
+	defer good.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	chainID, err := hex.DecodeString(testChainID)
+	require.NoError(t, err)
+	api := types.NewPool(chainID, dead.URL, wrong.URL, lagging.URL, good.URL)
+
+	for i := 0; i < 3; i++ {
+		acct, err := api.GetAccount(types.AN("eosio"))
+		require.NoError(t, err)
+		assert.Equal(t, types.AN("eosio"), acct.AccountName)
+	}
+
+	assert.Equal(t, int32(0), wrongHits)
+	assert.Equal(t, int32(0), laggingHits)
+	assert.Equal(t, int32(3), goodHits)
+
+	stats := api.Pool.Stats()
+	require.Len(t, stats, 4)
+	assert.Equal(t, 1, stats[0].Failures)
+	assert.True(t, stats[1].WrongChain)
+	assert.Equal(t, uint32(100), stats[2].HeadLag)
+	assert.Equal(t, uint32(0), stats[3].HeadLag)
+}
+
+func TestPool_NoHealthyEndpoint(t *testing.T) {
+	var hits int32
+	wrong := newTestNode(t, testChainID, 1000, &hits)
+	defer wrong.Close()
+
+	api := types.NewPool(types.SHA256Bytes{0x01}, wrong.URL)
+
+	_, err := api.GetAccount(types.AN("eosio"))
+	assert.Equal(t, types.ErrNoHealthyEndpoint, err)
+	assert.Equal(t, int32(0), hits)
+}
+
+func TestPool_FailoverOnTransportError(t *testing.T) {
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/chain/get_info" {
+			fmt.Fprintf(w, `{"chain_id":%q,"head_block_num":1000}`, testChainID)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	}))
+	defer flaky.Close()
+
+	// Slower to answer `get_info`, so it ranks after the flaky one.
+	var hits int32
+	node := newTestNode(t, testChainID, 1000, &hits)
+	defer node.Close()
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/chain/get_info" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		node.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer good.Close()
+
+	api := types.NewPool(nil, flaky.URL, good.URL)
+
+	_, err := api.GetAccount(types.AN("eosio"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), hits)
+	assert.Equal(t, 1, api.Pool.Stats()[0].Failures)
+}
+
+func TestPool_NoFailoverOnWrites(t *testing.T) {
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/chain/get_info" {
+			fmt.Fprintf(w, `{"chain_id":%q,"head_block_num":1000}`, testChainID)
+			return
+		}
+		// The push may have been accepted before the connection dropped.
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	}))
+	defer flaky.Close()
+
+	var pushes int32
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/chain/get_info" {
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprintf(w, `{"chain_id":%q,"head_block_num":1000}`, testChainID)
+			return
+		}
+		atomic.AddInt32(&pushes, 1)
+		fmt.Fprint(w, `{"transaction_id":"00"}`)
+	}))
+	defer good.Close()
+
+	api := types.NewPool(nil, flaky.URL, good.URL)
+
+	_, err := api.PushTransaction(&types.PackedTransaction{Compression: types.CompressionNone})
+	var transportErr *types.TransportError
+	assert.True(t, errors.As(err, &transportErr), "got %v", err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&pushes))
+}
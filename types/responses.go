@@ -93,6 +93,10 @@ type TransactionTrace struct {
 	TotalCPUUsage int                 `json:"total_cpu_usage"`
 	TransactionID SHA256Bytes         `json:"trx_id"`
 	InlineTraces  []*TransactionTrace `json:"inline_traces"`
+
+	// DecodeErr tells why the data of Action couldn't be decoded into
+	// its registered type, Action.Data keeping the data as received.
+	DecodeErr error `json:"-"`
 }
 
 type TransactionTraceAuthSequence struct {
@@ -139,6 +143,50 @@ type TransactionsResp struct {
 	Transactions []SequencedTransactionResp
 }
 
+type GetActionsRequest struct {
+	AccountName AccountName `json:"account_name"`
+	Pos         int64       `json:"pos"`    // account sequence to start from, -1 for the last action
+	Offset      int64       `json:"offset"` // relative position of the other end of the range, negative to go back
+}
+
+type GetActionsResp struct {
+	Actions                []ActionResp `json:"actions"` // ordered by account sequence
+	LastIrreversibleBlock  uint32       `json:"last_irreversible_block"`
+	TimeLimitExceededError bool         `json:"time_limit_exceeded_error"`
+}
+
+type ActionResp struct {
+	GlobalSeq  int64            `json:"global_action_seq"`
+	AccountSeq int64            `json:"account_action_seq"`
+	BlockNum   uint32           `json:"block_num"`
+	BlockTime  BlockTimestamp   `json:"block_time"`
+	Trace      TransactionTrace `json:"action_trace"`
+}
+
+type KeyAccountsResp struct {
+	AccountNames []AccountName `json:"account_names"`
+}
+
+type ControlledAccountsResp struct {
+	ControlledAccounts []AccountName `json:"controlled_accounts"`
+}
+
+type AccountsByAuthorizersResp struct {
+	Accounts []AccountAuthorizer `json:"accounts"`
+}
+
+// AccountAuthorizer is a permission of `AccountName` satisfied in part
+// by either `AuthorizingAccount` or `AuthorizingKey`, with `Weight`
+// toward `Threshold`.
+type AccountAuthorizer struct {
+	AccountName        AccountName      `json:"account_name"`
+	PermissionName     PermissionName   `json:"permission_name"`
+	AuthorizingAccount *PermissionLevel `json:"authorizing_account,omitempty"`
+	AuthorizingKey     *ecc.PublicKey   `json:"authorizing_key,omitempty"`
+	Weight             uint16           `json:"weight"`
+	Threshold          uint32           `json:"threshold"`
+}
+
 type ProducerChange struct {
 }
 
@@ -159,6 +207,9 @@ type AccountResp struct {
 	VoterInfo          VoterInfo            `json:"voter_info"`
 }
 
+// CurrencyBalanceResp is the layout of balances before `eosio.token`.
+//
+// Deprecated: GetCurrencyBalance returns a list of Asset.
 type CurrencyBalanceResp struct {
 	EOSBalance        Asset    `json:"eos_balance"`
 	StakedBalance     Asset    `json:"staked_balance"`
@@ -166,6 +217,19 @@ type CurrencyBalanceResp struct {
 	LastUnstakingTime JSONTime `json:"last_unstaking_time"`
 }
 
+// CurrencyStats is the `stat` row of an `eosio.token` compatible
+// contract.
+type CurrencyStats struct {
+	Supply    Asset       `json:"supply"`
+	MaxSupply Asset       `json:"max_supply"`
+	Issuer    AccountName `json:"issuer"`
+}
+
+type WalletStatus struct {
+	Name     string
+	Unlocked bool
+}
+
 type GetTableRowsRequest struct {
 	JSON       bool   `json:"json"`
 	Scope      string `json:"scope"`
@@ -175,11 +239,40 @@ type GetTableRowsRequest struct {
 	LowerBound string `json:"lower_bound"`
 	UpperBound string `json:"upper_bound"`
 	Limit      uint32 `json:"limit,omitempty"` // defaults to 10 => chain_plugin.hpp:struct get_table_rows_params
+	Reverse    bool   `json:"reverse,omitempty"`
+
+	// Secondary index queries, see SetIndex.
+	IndexPosition string `json:"index_position,omitempty"` // "primary" (default), "secondary", "tertiary"... or "1", "2"...
+	KeyType       string `json:"key_type,omitempty"`       // "i64", "i128", "i256", "float64", "float128", "sha256", "ripemd160" or "name"
+	EncodeType    string `json:"encode_type,omitempty"`    // "dec" (default) or "hex"
 }
 
 type GetTableRowsResp struct {
-	More bool            `json:"more"`
-	Rows json.RawMessage `json:"rows"` // defer loading, as it depends on `JSON` being true/false.
+	More    bool            `json:"more"`
+	NextKey string          `json:"next_key"` // only returned by recent `nodeos`
+	Rows    json.RawMessage `json:"rows"`     // defer loading, as it depends on `JSON` being true/false.
+}
+
+type GetTableByScopeRequest struct {
+	Code       string `json:"code"`
+	Table      string `json:"table,omitempty"` // all the tables of `code` if empty
+	LowerBound string `json:"lower_bound,omitempty"`
+	UpperBound string `json:"upper_bound,omitempty"`
+	Limit      uint32 `json:"limit,omitempty"` // defaults to 10
+	Reverse    bool   `json:"reverse,omitempty"`
+}
+
+type GetTableByScopeResp struct {
+	More string       `json:"more"` // scope to start the next page from, empty on the last page
+	Rows []TableScope `json:"rows"`
+}
+
+type TableScope struct {
+	Code  AccountName `json:"code"`
+	Scope ScopeName   `json:"scope"`
+	Table TableName   `json:"table"`
+	Payer AccountName `json:"payer"`
+	Count uint32      `json:"count"`
 }
 
 func (resp *GetTableRowsResp) JSONToStructs(v interface{}) error {
@@ -236,6 +329,31 @@ type PushTransactionFullResp struct {
 	Processed     TransactionProcessed `json:"processed"` // WARN: is an `fc::variant` in server..
 }
 
+type ComputeTransactionResp struct {
+	TransactionID SHA256Bytes `json:"transaction_id"`
+	Processed     struct {
+		Receipt         *TransactionReceiptHeader `json:"receipt"`
+		Elapsed         int64                     `json:"elapsed"`
+		NetUsage        uint64                    `json:"net_usage"`
+		ActionTraces    []ComputedActionTrace     `json:"action_traces"`
+		AccountRAMDelta *AccountRAMDelta          `json:"account_ram_delta"`
+		Except          json.RawMessage           `json:"except"`
+	} `json:"processed"`
+}
+
+type ComputedActionTrace struct {
+	Receiver         AccountName       `json:"receiver"`
+	Action           *Action           `json:"act"`
+	Elapsed          int64             `json:"elapsed"`
+	Console          string            `json:"console"`
+	AccountRAMDeltas []AccountRAMDelta `json:"account_ram_deltas"`
+}
+
+type AccountRAMDelta struct {
+	Account AccountName `json:"account"`
+	Delta   int64       `json:"delta"`
+}
+
 type TransactionProcessed struct {
 	Status               string        `json:"status"`
 	ID                   SHA256Bytes   `json:"id"`
@@ -339,3 +457,123 @@ type Producer struct {
 type ProducersResp struct {
 	Producers []Producer `json:"producers"`
 }
+
+// ProducerRuntimeOptions are the options of the producer plugin that
+// can be changed while `nodeos` runs.  Nil fields are left untouched
+// by UpdateProducerRuntimeOptions.
+type ProducerRuntimeOptions struct {
+	MaxTransactionTime                  *int32       `json:"max_transaction_time,omitempty"`       // ms
+	MaxIrreversibleBlockAge             *int32       `json:"max_irreversible_block_age,omitempty"` // seconds
+	ProduceTimeOffsetUS                 *int32       `json:"produce_time_offset_us,omitempty"`
+	LastBlockTimeOffsetUS               *int32       `json:"last_block_time_offset_us,omitempty"`
+	MaxScheduledTransactionTimePerBlock *int32       `json:"max_scheduled_transaction_time_per_block_ms,omitempty"`
+	SubjectiveCPULeewayUS               *int32       `json:"subjective_cpu_leeway_us,omitempty"`
+	IncomingDeferRatio                  *JSONFloat64 `json:"incoming_defer_ratio,omitempty"`
+	GreylistLimit                       *uint32      `json:"greylist_limit,omitempty"`
+}
+
+type ProducerGreylist struct {
+	Accounts []AccountName `json:"accounts"`
+}
+
+// WhitelistBlacklist holds the filters of a node. Nil lists are left
+// untouched by SetWhitelistBlacklist.
+type WhitelistBlacklist struct {
+	ActorWhitelist    []AccountName       `json:"actor_whitelist"`
+	ActorBlacklist    []AccountName       `json:"actor_blacklist"`
+	ContractWhitelist []AccountName       `json:"contract_whitelist"`
+	ContractBlacklist []AccountName       `json:"contract_blacklist"`
+	ActionBlacklist   []BlacklistedAction `json:"action_blacklist"`
+	KeyBlacklist      []ecc.PublicKey     `json:"key_blacklist"`
+}
+
+// MarshalJSON leaves out the nil lists, but keeps the empty ones.
+func (l WhitelistBlacklist) MarshalJSON() ([]byte, error) {
+	out := M{}
+	if l.ActorWhitelist != nil {
+		out["actor_whitelist"] = l.ActorWhitelist
+	}
+	if l.ActorBlacklist != nil {
+		out["actor_blacklist"] = l.ActorBlacklist
+	}
+	if l.ContractWhitelist != nil {
+		out["contract_whitelist"] = l.ContractWhitelist
+	}
+	if l.ContractBlacklist != nil {
+		out["contract_blacklist"] = l.ContractBlacklist
+	}
+	if l.ActionBlacklist != nil {
+		out["action_blacklist"] = l.ActionBlacklist
+	}
+	if l.KeyBlacklist != nil {
+		out["key_blacklist"] = l.KeyBlacklist
+	}
+	return json.Marshal(out)
+}
+
+// BlacklistedAction is an action of a contract, encoded as
+// `["account", "action"]`.
+type BlacklistedAction struct {
+	Account AccountName
+	Action  ActionName
+}
+
+func (a *BlacklistedAction) UnmarshalJSON(data []byte) error {
+	var pair []string
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+
+	if len(pair) != 2 {
+		return fmt.Errorf("expected 2 items, received %d", len(pair))
+	}
+
+	*a = BlacklistedAction{AccountName(pair[0]), ActionName(pair[1])}
+
+	return nil
+}
+
+func (a BlacklistedAction) MarshalJSON() (data []byte, err error) {
+	return json.Marshal([]string{string(a.Account), string(a.Action)})
+}
+
+type SnapshotResp struct {
+	HeadBlockID   SHA256Bytes    `json:"head_block_id"`
+	HeadBlockNum  uint32         `json:"head_block_num"`  // only returned by recent `nodeos`
+	HeadBlockTime BlockTimestamp `json:"head_block_time"` // only returned by recent `nodeos`
+	Version       uint32         `json:"version"`         // only returned by recent `nodeos`
+	SnapshotName  string         `json:"snapshot_name"`   // path of the snapshot on the node
+}
+
+type IntegrityHashResp struct {
+	HeadBlockID   SHA256Bytes `json:"head_block_id"`
+	IntegrityHash SHA256Bytes `json:"integrity_hash"`
+}
+
+type ProtocolFeatureActivations struct {
+	ProtocolFeaturesToActivate []SHA256Bytes `json:"protocol_features_to_activate"`
+}
+
+type GetSupportedProtocolFeaturesRequest struct {
+	ExcludeDisabled      bool `json:"exclude_disabled"`
+	ExcludeUnactivatable bool `json:"exclude_unactivatable"`
+}
+
+type ProtocolFeature struct {
+	FeatureDigest          SHA256Bytes `json:"feature_digest"`
+	SubjectiveRestrictions struct {
+		Enabled                       bool     `json:"enabled"`
+		PreactivationRequired         bool     `json:"preactivation_required"`
+		EarliestAllowedActivationTime JSONTime `json:"earliest_allowed_activation_time"`
+	} `json:"subjective_restrictions"`
+	DescriptionDigest   SHA256Bytes                    `json:"description_digest"`
+	Dependencies        []SHA256Bytes                  `json:"dependencies"`
+	ProtocolFeatureType string                         `json:"protocol_feature_type"` // "builtin"
+	Specification       []ProtocolFeatureSpecification `json:"specification"`
+}
+
+// ProtocolFeatureSpecification is like `{"name": "builtin_feature_codename", "value": "ONLY_BILL_FIRST_AUTHORIZER"}`.
+type ProtocolFeatureSpecification struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
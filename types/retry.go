@@ -0,0 +1,224 @@
+package types
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// RetryPolicy describes how failed calls are retried.  Set it on
+// `API.Retry` to enable retries: read calls (`get_*` and friends) are
+// then retried automatically, and PushTransaction is retried only
+// after checking on chain that the transaction didn't make it.
+//
+// Other calls that change state (`producer`, `wallet` and `net`
+// mutations) are never retried.
+type RetryPolicy struct {
+	MaxAttempts    int           // including the first attempt. Values of 1 or less disable retries
+	InitialBackoff time.Duration // defaults to 100ms
+	MaxBackoff     time.Duration // defaults to 5s
+	Multiplier     float64       // defaults to 2
+	Jitter         float64       // ratio of the backoff randomly added or removed, between 0 and 1
+
+	// Retryable decides which errors are worth another attempt.
+	// Defaults to IsRetryableError.
+	Retryable func(err error) bool
+}
+
+// NewRetryPolicy returns a policy doing up to `maxAttempts` with
+// exponential backoff from 100ms to 5s, and 20% jitter.
+func NewRetryPolicy(maxAttempts int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// IsRetryableError is the default error classification: transport
+// errors, rate limiting and 5xx answers not coming from `nodeos`
+// itself (gateways, load balancers) are transient. Errors from
+// `nodeos` (APIError with a name) and context cancellation are not.
+func IsRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Code == 429 {
+			return true
+		}
+		return apiErr.Code >= 500 && apiErr.ErrorStruct.Name == ""
+	}
+
+	return false
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryableError(err)
+}
+
+// Backoff returns the delay before attempt number `attempt` (the
+// second attempt is 1).
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	initial := p.InitialBackoff
+	if initial == 0 {
+		initial = 100 * time.Millisecond
+	}
+	max := p.MaxBackoff
+	if max == 0 {
+		max = 5 * time.Second
+	}
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+
+	backoff := float64(initial)
+	for i := 1; i < attempt; i++ {
+		backoff *= multiplier
+		if backoff > float64(max) {
+			backoff = float64(max)
+			break
+		}
+	}
+
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	return time.Duration(backoff)
+}
+
+// do runs `f` until it succeeds, fails with a non-retryable error, or
+// attempts are exhausted.
+func (p *RetryPolicy) do(ctx context.Context, f func(attempt int) error) (err error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, p.Backoff(attempt)); err != nil {
+				return err
+			}
+		}
+
+		err = f(attempt)
+
+		var stop *nonRetryableError
+		if errors.As(err, &stop) {
+			return stop.err
+		}
+
+		if err == nil || attempt+1 >= p.MaxAttempts || !p.retryable(err) {
+			return err
+		}
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isReadCall tells whether a call can be sent again without
+// side effects.
+func isReadCall(baseAPI, endpoint string) bool {
+	switch baseAPI {
+	case "chain", "history", "account_history":
+		return strings.HasPrefix(endpoint, "get_") || endpoint == "abi_json_to_bin" || endpoint == "abi_bin_to_json" || endpoint == "compute_transaction"
+	case "wallet":
+		return endpoint == "get_public_keys" || endpoint == "list_wallets"
+	case "producer":
+		return endpoint == "paused" || strings.HasPrefix(endpoint, "get_")
+	case "net":
+		return endpoint == "connections" || endpoint == "status"
+	}
+	return false
+}
+
+// pushTransaction submits `tx`, retrying under `api.Retry`.  Before
+// each new attempt, the chain is asked about the transaction ID: if
+// it's known there, the previous attempt went through and we stop.
+// If the chain can't tell, we don't take the chance of submitting
+// twice and the original error is returned.  Resubmission always
+// sends the same packed bytes, never a newly signed transaction.  See
+// SubmitTransaction to keep resubmitting until expiration instead.
+func (api *API) pushTransaction(ctx context.Context, tx *PackedTransaction, out interface{}) error {
+	if api.Retry == nil {
+		return api.callOnce(ctx, "chain", "push_transaction", tx, out)
+	}
+
+	txID, err := tx.TransactionID()
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	return api.Retry.do(ctx, func(attempt int) error {
+		if attempt > 0 {
+			known, err := api.transactionKnown(ctx, txID)
+			if err != nil {
+				return &nonRetryableError{lastErr}
+			}
+			if known {
+				return fillPushResp(out, txID)
+			}
+		}
+
+		err := api.callOnce(ctx, "chain", "push_transaction", tx, out)
+		if attempt > 0 && errors.Is(err, ErrTxDuplicate) {
+			// It was the previous attempt that went through.
+			return fillPushResp(out, txID)
+		}
+		lastErr = err
+		return err
+	})
+}
+
+func (api *API) transactionKnown(ctx context.Context, txID SHA256Bytes) (bool, error) {
+	var resp *TransactionResp
+	err := api.callOnce(ctx, "history", "get_transaction", M{"id": txID}, &resp)
+	// A 404 (ErrNotFound) is a node without the history API, which
+	// can't tell: only its own errors mean the transaction isn't there.
+	if errors.Is(err, ErrTxNotFound) || errors.Is(err, ErrUnknownTransaction) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func fillPushResp(out interface{}, txID SHA256Bytes) error {
+	if resp, ok := out.(**PushTransactionFullResp); ok {
+		*resp = &PushTransactionFullResp{TransactionID: hex.EncodeToString(txID)}
+	}
+	return nil
+}
+
+// nonRetryableError stops a RetryPolicy loop, surfacing `err`.
+type nonRetryableError struct {
+	err error
+}
+
+func (e *nonRetryableError) Error() string { return e.err.Error() }
+func (e *nonRetryableError) Unwrap() error { return e.err }
@@ -0,0 +1,159 @@
+package types_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Akagi201/eosgo/token"
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRetryPolicy() *types.RetryPolicy {
+	policy := types.NewRetryPolicy(3)
+	policy.InitialBackoff = time.Millisecond
+	return policy
+}
+
+func TestRetry_ReadCall(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(503)
+			return
+		}
+		fmt.Fprint(w, `{"head_block_num":10}`)
+	}))
+	defer server.Close()
+
+	api := types.New(server.URL)
+	api.Retry = testRetryPolicy()
+
+	info, err := api.GetInfo()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), info.HeadBlockNum)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_NodeosErrorNotRetried(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(500)
+		fmt.Fprint(w, `{"code":500,"message":"Internal Service Error","error":{"code":3100002,"name":"unknown_block_exception","what":"Unknown block"}}`)
+	}))
+	defer server.Close()
+
+	api := types.New(server.URL)
+	api.Retry = testRetryPolicy()
+
+	_, err := api.GetBlockByNum(1)
+	assert.True(t, errors.Is(err, types.ErrUnknownBlock))
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetry_PushTransaction(t *testing.T) {
+	tests := []struct {
+		name        string
+		historyResp func(w http.ResponseWriter)
+		expectPush  int
+		expectErr   bool
+	}{
+		{
+			name: "not on chain, resubmitted",
+			historyResp: func(w http.ResponseWriter) {
+				w.WriteHeader(500)
+				fmt.Fprint(w, `{"code":500,"message":"Internal Service Error","error":{"code":3040011,"name":"tx_not_found","what":"The transaction can not be found"}}`)
+			},
+			expectPush: 2,
+		},
+		{
+			name:        "no history API",
+			historyResp: func(w http.ResponseWriter) { w.WriteHeader(404) },
+			expectPush:  1,
+			expectErr:   true,
+		},
+		{
+			name:        "already on chain",
+			historyResp: func(w http.ResponseWriter) { fmt.Fprint(w, `{"id":"00"}`) },
+			expectPush:  1,
+		},
+		{
+			name: "chain can't tell",
+			historyResp: func(w http.ResponseWriter) {
+				w.WriteHeader(500)
+				fmt.Fprint(w, `{"code":500,"message":"Internal Service Error","error":{"code":3000000,"name":"plugin_exception","what":"history plugin disabled"}}`)
+			},
+			expectPush: 1,
+			expectErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pushes := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/chain/push_transaction":
+					pushes++
+					if pushes == 1 {
+						conn, _, _ := w.(http.Hijacker).Hijack()
+						conn.Close()
+						return
+					}
+					fmt.Fprint(w, `{"transaction_id":"abcd"}`)
+				case "/v1/history/get_transaction":
+					test.historyResp(w)
+				}
+			}))
+			defer server.Close()
+
+			api := types.New(server.URL)
+			api.Retry = testRetryPolicy()
+
+			packed := &types.PackedTransaction{PackedTransaction: []byte{0x01, 0x02}}
+			resp, err := api.PushTransaction(packed)
+			assert.Equal(t, test.expectPush, pushes)
+			if test.expectErr {
+				var transportErr *types.TransportError
+				assert.True(t, errors.As(err, &transportErr))
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, resp.TransactionID)
+		})
+	}
+}
+
+func TestRetry_PushTransaction_Zlib(t *testing.T) {
+	node, api := newSubmitNode(t)
+	api.Retry = testRetryPolicy()
+	transport := &lossyTransport{next: api.HttpClient.Transport, fail: 1, delivered: true}
+	api.HttpClient.Transport = transport
+
+	opts := &types.TxOptions{}
+	require.NoError(t, opts.FillFromChain(api))
+	tx := types.NewTransaction([]*types.Action{token.NewTransfer("alice", "bob", types.NewEOSAsset(1), "")}, opts)
+	signedTx, packed, err := api.SignTransaction(tx, opts.ChainID, types.CompressionZlib)
+	require.NoError(t, err)
+
+	// The ID is the hash of the transaction, not of the compressed bytes.
+	rawtrx, err := types.MarshalBinary(signedTx.Transaction)
+	require.NoError(t, err)
+	id := sha256.Sum256(rawtrx)
+
+	resp, err := api.PushTransaction(packed)
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(id[:]), resp.TransactionID)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&transport.pushes), "found on chain, not sent again")
+	assert.Len(t, node.Pushed(), 1)
+}
@@ -2,7 +2,9 @@ package types
 
 import (
 	"bufio"
+	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -22,28 +24,82 @@ type Signer interface {
 	ImportPrivateKey(wifPrivKey string) error
 }
 
+// ContextSigner is implemented by signers that go over the network
+// (like the WalletSigner), so cancellation and deadlines of `ctx` can
+// reach the underlying HTTP requests.
+type ContextSigner interface {
+	Signer
+
+	AvailableKeysContext(ctx context.Context) (out []ecc.PublicKey, err error)
+	SignContext(ctx context.Context, tx *SignedTransaction, chainID []byte, requiredKeys ...ecc.PublicKey) (*SignedTransaction, error)
+	ImportPrivateKeyContext(ctx context.Context, wifPrivKey string) error
+}
+
+func availableKeysWithContext(ctx context.Context, s Signer) ([]ecc.PublicKey, error) {
+	if cs, ok := s.(ContextSigner); ok {
+		return cs.AvailableKeysContext(ctx)
+	}
+	return s.AvailableKeys()
+}
+
+func signWithContext(ctx context.Context, s Signer, tx *SignedTransaction, chainID []byte, requiredKeys ...ecc.PublicKey) (*SignedTransaction, error) {
+	if cs, ok := s.(ContextSigner); ok {
+		return cs.SignContext(ctx, tx, chainID, requiredKeys...)
+	}
+	return s.Sign(tx, chainID, requiredKeys...)
+}
+
+// PasswordProvider returns the password of a wallet, so a WalletSigner
+// can unlock it.
+type PasswordProvider func(ctx context.Context, walletName string) (string, error)
+
 // `eosiowd` wallet-based signer
 type WalletSigner struct {
-	api        *API
-	walletName string
+	api              *API
+	walletName       string
+	passwordProvider PasswordProvider
 }
 
 // NewWalletSigner takes an `api`, because often the wallet will be a
 // second endpoint, and not the server node with whom you're pushing
 // transactions to.
 func NewWalletSigner(api *API, walletName string) *WalletSigner {
-	return &WalletSigner{api, walletName}
+	return &WalletSigner{api: api, walletName: walletName}
+}
+
+// SetPasswordProvider makes the signer unlock its wallet, when locked,
+// before listing keys or signing.
+func (s *WalletSigner) SetPasswordProvider(p PasswordProvider) {
+	s.passwordProvider = p
 }
 
 func (s *WalletSigner) ImportPrivateKey(wifKey string) (err error) {
-	return s.api.WalletImportKey(s.walletName, wifKey)
+	return s.ImportPrivateKeyContext(context.Background(), wifKey)
+}
+
+func (s *WalletSigner) ImportPrivateKeyContext(ctx context.Context, wifKey string) (err error) {
+	if err := s.ensureUnlocked(ctx); err != nil {
+		return err
+	}
+	return s.api.WalletImportKeyContext(ctx, s.walletName, wifKey)
 }
 
 func (s *WalletSigner) AvailableKeys() (out []ecc.PublicKey, err error) {
-	return s.api.WalletPublicKeys()
+	return s.AvailableKeysContext(context.Background())
+}
+
+func (s *WalletSigner) AvailableKeysContext(ctx context.Context) (out []ecc.PublicKey, err error) {
+	if err := s.ensureUnlocked(ctx); err != nil {
+		return nil, err
+	}
+	return s.api.WalletPublicKeysContext(ctx)
 }
 
 func (s *WalletSigner) Sign(tx *SignedTransaction, chainID []byte, requiredKeys ...ecc.PublicKey) (*SignedTransaction, error) {
+	return s.SignContext(context.Background(), tx, chainID, requiredKeys...)
+}
+
+func (s *WalletSigner) SignContext(ctx context.Context, tx *SignedTransaction, chainID []byte, requiredKeys ...ecc.PublicKey) (*SignedTransaction, error) {
 	// Fetch the available keys over there... and ask this wallet
 	// provider to sign with the keys he has..
 
@@ -51,7 +107,11 @@ func (s *WalletSigner) Sign(tx *SignedTransaction, chainID []byte, requiredKeys
 	// and the available keys, return something about
 	// `SignatureIncomplete`.
 
-	resp, err := s.api.WalletSignTransaction(tx, chainID, requiredKeys...)
+	if err := s.ensureUnlocked(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.api.WalletSignTransactionContext(ctx, tx, chainID, requiredKeys...)
 	if err != nil {
 		return nil, err
 	}
@@ -61,6 +121,48 @@ func (s *WalletSigner) Sign(tx *SignedTransaction, chainID []byte, requiredKeys
 	return tx, nil
 }
 
+// SignDigest signs a raw 32 bytes digest with the private key of
+// `requiredKey`.
+func (s *WalletSigner) SignDigest(digest []byte, requiredKey ecc.PublicKey) (ecc.Signature, error) {
+	return s.SignDigestContext(context.Background(), digest, requiredKey)
+}
+
+func (s *WalletSigner) SignDigestContext(ctx context.Context, digest []byte, requiredKey ecc.PublicKey) (ecc.Signature, error) {
+	if err := s.ensureUnlocked(ctx); err != nil {
+		return ecc.Signature{}, err
+	}
+	return s.api.WalletSignDigestContext(ctx, digest, requiredKey)
+}
+
+// ensureUnlocked unlocks the wallet through the password provider, if
+// any, when it's not listed as unlocked by `keosd`.
+func (s *WalletSigner) ensureUnlocked(ctx context.Context) error {
+	if s.passwordProvider == nil {
+		return nil
+	}
+
+	wallets, err := s.api.WalletListContext(ctx)
+	if err != nil {
+		return fmt.Errorf("listing wallets: %w", err)
+	}
+	for _, wallet := range wallets {
+		if wallet.Name == s.walletName && wallet.Unlocked {
+			return nil
+		}
+	}
+
+	password, err := s.passwordProvider(ctx, s.walletName)
+	if err != nil {
+		return fmt.Errorf("password for wallet %q: %w", s.walletName, err)
+	}
+
+	err = s.api.WalletUnlockContext(ctx, s.walletName, password)
+	if err != nil && !errors.Is(err, ErrWalletUnlocked) {
+		return fmt.Errorf("unlocking wallet %q: %w", s.walletName, err)
+	}
+	return nil
+}
+
 // KeyBag, local signing - NOT COMPLETE
 
 // KeyBag holds private keys in memory, for signing transactions.
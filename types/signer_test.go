@@ -0,0 +1,162 @@
+package types_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Akagi201/eosgo/ecc"
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPubKey = "EOS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV"
+
+var testSignature = ecc.Signature{Curve: ecc.CurveK1, Content: bytes.Repeat([]byte{1}, 65)}.String()
+
+// testKeosd fakes a `keosd` with a single wallet.
+type testKeosd struct {
+	t        *testing.T
+	password string
+	unlocked bool
+	calls    []string
+	bodies   map[string]string
+}
+
+func (k *testKeosd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint := strings.TrimPrefix(r.URL.Path, "/v1/wallet/")
+	body, _ := ioutil.ReadAll(r.Body)
+	k.calls = append(k.calls, endpoint)
+	k.bodies[endpoint] = string(body)
+
+	locked := func() {
+		w.WriteHeader(500)
+		w.Write([]byte(`{"code":500,"message":"Internal Service Error","error":{"code":3120003,"name":"wallet_locked_exception","what":"Locked wallet","details":[]}}`))
+	}
+
+	switch endpoint {
+	case "create":
+		w.Write([]byte(`"PW5secret"`))
+	case "open", "lock_all", "set_timeout":
+		w.Write([]byte(`{}`))
+	case "lock":
+		k.unlocked = false
+		w.Write([]byte(`{}`))
+	case "unlock":
+		var params []string
+		require.NoError(k.t, json.Unmarshal(body, &params))
+		if params[1] != k.password {
+			w.WriteHeader(500)
+			w.Write([]byte(`{"code":500,"message":"Internal Service Error","error":{"code":3120005,"name":"wallet_invalid_password_exception","what":"Invalid wallet password","details":[]}}`))
+			return
+		}
+		k.unlocked = true
+		w.Write([]byte(`{}`))
+	case "list_wallets":
+		if k.unlocked {
+			w.Write([]byte(`["default *"]`))
+		} else {
+			w.Write([]byte(`["default"]`))
+		}
+	case "create_key":
+		w.Write([]byte(`"` + testPubKey + `"`))
+	case "remove_key":
+		w.Write([]byte(`{}`))
+	case "get_public_keys":
+		if !k.unlocked {
+			locked()
+			return
+		}
+		w.Write([]byte(`["` + testPubKey + `"]`))
+	case "sign_digest":
+		if !k.unlocked {
+			locked()
+			return
+		}
+		w.Write([]byte(`"` + testSignature + `"`))
+	default:
+		k.t.Errorf("unexpected call to %s", r.URL.Path)
+	}
+}
+
+func TestAPI_Wallet(t *testing.T) {
+	keosd := &testKeosd{t: t, password: "PW5secret", bodies: map[string]string{}}
+	server := httptest.NewServer(keosd)
+	defer server.Close()
+
+	api := types.New(server.URL)
+	pubKey, err := ecc.NewPublicKey(testPubKey)
+	require.NoError(t, err)
+
+	password, err := api.WalletCreate("default")
+	require.NoError(t, err)
+	assert.Equal(t, "PW5secret", password)
+	assert.Equal(t, `"default"`, keosd.bodies["create"])
+
+	require.NoError(t, api.WalletOpen("default"))
+	require.NoError(t, api.WalletLock("default"))
+	require.NoError(t, api.WalletLockAll())
+
+	err = api.WalletUnlock("default", "wrong")
+	assert.True(t, errors.Is(err, types.ErrWalletInvalidPassword))
+	require.NoError(t, api.WalletUnlock("default", "PW5secret"))
+
+	wallets, err := api.WalletList()
+	require.NoError(t, err)
+	assert.Equal(t, []types.WalletStatus{{Name: "default", Unlocked: true}}, wallets)
+
+	key, err := api.WalletCreateKey("default", "")
+	require.NoError(t, err)
+	assert.Equal(t, testPubKey, key.String())
+	assert.Equal(t, `["default","K1"]`, keosd.bodies["create_key"])
+
+	require.NoError(t, api.WalletRemoveKey("default", "PW5secret", pubKey))
+	assert.Equal(t, `["default","PW5secret","`+testPubKey+`"]`, keosd.bodies["remove_key"])
+
+	require.NoError(t, api.WalletSetTimeout(90*time.Second))
+	assert.Equal(t, `90`, keosd.bodies["set_timeout"])
+
+	sig, err := api.WalletSignDigest(make([]byte, 32), pubKey)
+	require.NoError(t, err)
+	assert.Equal(t, testSignature, sig.String())
+	assert.Equal(t, `["0000000000000000000000000000000000000000000000000000000000000000","`+testPubKey+`"]`, keosd.bodies["sign_digest"])
+}
+
+func TestWalletSigner_PasswordProvider(t *testing.T) {
+	keosd := &testKeosd{t: t, password: "PW5secret", bodies: map[string]string{}}
+	server := httptest.NewServer(keosd)
+	defer server.Close()
+
+	api := types.New(server.URL)
+	pubKey, err := ecc.NewPublicKey(testPubKey)
+	require.NoError(t, err)
+
+	signer := types.NewWalletSigner(api, "default")
+	_, err = signer.SignDigest(make([]byte, 32), pubKey)
+	assert.True(t, errors.Is(err, types.ErrWalletLocked))
+
+	var asked int
+	signer.SetPasswordProvider(func(ctx context.Context, walletName string) (string, error) {
+		asked++
+		assert.Equal(t, "default", walletName)
+		return "PW5secret", nil
+	})
+
+	sig, err := signer.SignDigest(make([]byte, 32), pubKey)
+	require.NoError(t, err)
+	assert.Equal(t, testSignature, sig.String())
+
+	keys, err := signer.AvailableKeys()
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	assert.Equal(t, 1, asked)
+}
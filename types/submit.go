@@ -0,0 +1,118 @@
+package types
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Nonce represents the `nonce` action of `eosio.null`, which does
+// nothing.  It makes otherwise identical transactions distinct, so
+// that sending the same transfer twice on purpose doesn't fail with
+// ErrTxDuplicate.
+type Nonce struct {
+	Value string `json:"value"`
+}
+
+// NewNonceAction returns an `eosio.null::nonce` action with a random
+// value.  It needs no authorization.  See TxOptions.Nonce.
+func NewNonceAction() *Action {
+	value := make([]byte, 16)
+	if _, err := rand.Read(value); err != nil {
+		panic(fmt.Sprintf("reading random nonce: %s", err))
+	}
+
+	return &Action{
+		Account:    AN("eosio.null"),
+		Name:       ActN("nonce"),
+		ActionData: NewActionData(Nonce{Value: hex.EncodeToString(value)}),
+	}
+}
+
+// SubmitTransaction pushes `tx` until it's known to be on chain, or
+// expired.  Unlike PushTransaction, it's meant for when a push failing
+// in an ambiguous way (a timeout, a gateway error) must neither be
+// reported as a failure while the transaction may have gone through,
+// nor be followed by a newly signed transaction, which could execute
+// twice:
+//
+//   - the transaction ID is computed before sending;
+//   - after an ambiguous failure, the chain is asked about that ID
+//     (this needs the history API), and the same packed bytes are
+//     sent again until the chain has the transaction or it expires;
+//   - ErrTxDuplicate means the transaction was already accepted, and
+//     is a success.
+//
+// The expiration is checked against the time of the node, estimated
+// from `get_info` (see API.InfoCache), not against the local clock.
+// Errors from `nodeos` rejecting the transaction are returned as is.
+// The delay between attempts follows `api.Retry`, or the defaults of
+// NewRetryPolicy; its MaxAttempts is ignored, the expiration of the
+// transaction bounding the attempts.  When the success is only known
+// from the chain, the response only has the TransactionID.
+func (api *API) SubmitTransaction(tx *PackedTransaction) (out *PushTransactionFullResp, err error) {
+	return api.SubmitTransactionContext(context.Background(), tx)
+}
+
+func (api *API) SubmitTransactionContext(ctx context.Context, tx *PackedTransaction) (out *PushTransactionFullResp, err error) {
+	txID, err := tx.TransactionID()
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := tx.Unpack()
+	if err != nil {
+		return nil, err
+	}
+	expiration := signedTx.Expiration.Time
+
+	policy := api.Retry
+	if policy == nil {
+		policy = NewRetryPolicy(0)
+	}
+
+	accepted := &PushTransactionFullResp{TransactionID: hex.EncodeToString(txID)}
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, policy.Backoff(attempt)); err != nil {
+				return nil, fmt.Errorf("%w, last error: %s", err, lastErr)
+			}
+
+			known, err := api.transactionKnown(ctx, txID)
+			if err == nil && known {
+				return accepted, nil
+			}
+			if api.nodeNow(ctx).After(expiration) {
+				if err != nil {
+					return nil, fmt.Errorf("transaction %x expired, but the chain can't tell if it went through: %w", []byte(txID), err)
+				}
+				return nil, fmt.Errorf("transaction %x expired, last error: %s: %w", []byte(txID), lastErr, ErrExpiredTx)
+			}
+			// When the chain can't tell, sending the same bytes
+			// again is still safe.
+		}
+
+		out = nil
+		err := api.callOnce(ctx, "chain", "push_transaction", tx, &out)
+		if err == nil {
+			return out, nil
+		}
+		if errors.Is(err, ErrTxDuplicate) {
+			return accepted, nil
+		}
+		if attempt > 0 && errors.Is(err, ErrExpiredTx) {
+			// A previous attempt may still have made it in a block
+			// before the expiration.
+			if known, _ := api.transactionKnown(ctx, txID); known {
+				return accepted, nil
+			}
+			return nil, err
+		}
+		if !policy.retryable(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+}
@@ -0,0 +1,202 @@
+package types_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Akagi201/eosgo/ecc"
+	"github.com/Akagi201/eosgo/nodeostest"
+	"github.com/Akagi201/eosgo/token"
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lossyTransport fails the first `fail` push_transaction calls with
+// a gateway error, after (`delivered`) or before they reach the node.
+type lossyTransport struct {
+	next      http.RoundTripper
+	fail      int32
+	delivered bool
+	noHistory bool // answers the history API with 404s
+	pushes    int32
+}
+
+func (l *lossyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if l.noHistory && strings.HasPrefix(req.URL.Path, "/v1/history/") {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(strings.NewReader("")), Request: req}, nil
+	}
+	if !strings.HasSuffix(req.URL.Path, "/push_transaction") || atomic.AddInt32(&l.pushes, 1) > l.fail {
+		return l.next.RoundTrip(req)
+	}
+
+	if l.delivered {
+		resp, err := l.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+	}
+	return &http.Response{
+		StatusCode: http.StatusBadGateway,
+		Body:       ioutil.NopCloser(strings.NewReader("<html>502 Bad Gateway</html>")),
+		Request:    req,
+	}, nil
+}
+
+func newSubmitNode(t *testing.T) (*nodeostest.Node, *types.API) {
+	node := nodeostest.NewNode()
+	t.Cleanup(node.Close)
+
+	key, err := ecc.NewRandomPrivateKey()
+	require.NoError(t, err)
+	node.CreateAccount("alice", key.PublicKey())
+	node.CreateAccount("bob", key.PublicKey())
+	require.NoError(t, node.Wallet.Add(key.String()))
+
+	api := node.API()
+	api.Retry = &types.RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	return node, api
+}
+
+func signTransfer(t *testing.T, api *types.API, opts *types.TxOptions) *types.PackedTransaction {
+	require.NoError(t, opts.FillFromChain(api))
+	tx := types.NewTransaction([]*types.Action{token.NewTransfer("alice", "bob", types.NewEOSAsset(1), "")}, opts)
+	_, packed, err := api.SignTransaction(tx, opts.ChainID, types.CompressionNone)
+	require.NoError(t, err)
+	return packed
+}
+
+func TestAPI_SubmitTransaction(t *testing.T) {
+	for _, delivered := range []bool{false, true} {
+		node, api := newSubmitNode(t)
+		transport := &lossyTransport{next: api.HttpClient.Transport, fail: 2, delivered: delivered}
+		api.HttpClient.Transport = transport
+
+		packed := signTransfer(t, api, &types.TxOptions{})
+		id, err := packed.TransactionID()
+		require.NoError(t, err)
+
+		resp, err := api.SubmitTransaction(packed)
+		require.NoError(t, err, "delivered: %t", delivered)
+		assert.Equal(t, hex.EncodeToString(id), resp.TransactionID)
+		require.Len(t, node.Pushed(), 1, "delivered: %t", delivered)
+
+		if delivered {
+			assert.Equal(t, int32(1), atomic.LoadInt32(&transport.pushes), "found on chain, not sent again")
+		} else {
+			assert.Equal(t, int32(3), atomic.LoadInt32(&transport.pushes))
+		}
+
+		// Submitting again is a success too.
+		resp, err = api.SubmitTransaction(packed)
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(id), resp.TransactionID)
+		assert.Len(t, node.Pushed(), 1)
+	}
+}
+
+func TestAPI_SubmitTransaction_Failures(t *testing.T) {
+	node, api := newSubmitNode(t)
+
+	// Rejections from nodeos are final.
+	packed := signTransfer(t, api, &types.TxOptions{HeadBlockID: make(types.SHA256Bytes, 32)})
+	_, err := api.SubmitTransaction(packed)
+	assert.True(t, errors.Is(err, types.ErrInvalidRefBlock), "got %v", err)
+
+	// Pushes never reaching the node are sent until expiration.
+	api.HttpClient.Transport = &lossyTransport{next: api.HttpClient.Transport, fail: 1000}
+	packed = signTransfer(t, api, &types.TxOptions{Expiration: 50 * time.Millisecond})
+	_, err = api.SubmitTransaction(packed)
+	assert.True(t, errors.Is(err, types.ErrExpiredTx), "got %v", err)
+	assert.Empty(t, node.Pushed())
+
+	// Without the history API, the chain can't tell.
+	api.HttpClient.Transport = &lossyTransport{next: node.API().HttpClient.Transport, fail: 1000, noHistory: true}
+	packed = signTransfer(t, api, &types.TxOptions{Expiration: 50 * time.Millisecond})
+	_, err = api.SubmitTransaction(packed)
+	assert.True(t, errors.Is(err, types.ErrNotFound), "got %v", err)
+	assert.False(t, errors.Is(err, types.ErrExpiredTx), "got %v", err)
+}
+
+func TestAPI_SubmitTransaction_NodeTime(t *testing.T) {
+	// The node is 10 seconds behind the local clock: the transaction
+	// expired 5 seconds ago locally, not for the node.
+	nodeOffset := -10 * time.Second
+	pushes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/chain/get_info":
+			json.NewEncoder(w).Encode(&types.InfoResp{HeadBlockTime: types.JSONTime{Time: time.Now().Add(nodeOffset).UTC()}})
+		case "/v1/history/get_transaction":
+			w.WriteHeader(500)
+			fmt.Fprint(w, `{"code":500,"message":"Internal Service Error","error":{"code":3040011,"name":"tx_not_found","what":"The transaction can not be found"}}`)
+		case "/v1/chain/push_transaction":
+			pushes++
+			if pushes == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			fmt.Fprint(w, `{"transaction_id":"abcd"}`)
+		}
+	}))
+	defer server.Close()
+
+	api := types.New(server.URL)
+	api.Retry = &types.RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	tx := types.NewTransaction([]*types.Action{token.NewTransfer("alice", "bob", types.NewEOSAsset(1), "")}, &types.TxOptions{HeadBlockID: make(types.SHA256Bytes, 32)})
+	tx.Expiration = types.JSONTime{Time: time.Now().Add(-5 * time.Second).UTC()}
+	packed, err := types.NewSignedTransaction(tx).Pack(types.CompressionNone)
+	require.NoError(t, err)
+
+	resp, err := api.SubmitTransaction(packed)
+	require.NoError(t, err)
+	assert.Equal(t, "abcd", resp.TransactionID)
+	assert.Equal(t, 2, pushes)
+
+	// The node is ahead: the transaction expired there.
+	nodeOffset, pushes = 10*time.Second, 0
+	api = types.New(server.URL)
+	api.Retry = &types.RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	tx.Expiration = types.JSONTime{Time: time.Now().Add(5 * time.Second).UTC()}
+	packed, err = types.NewSignedTransaction(tx).Pack(types.CompressionNone)
+	require.NoError(t, err)
+
+	_, err = api.SubmitTransaction(packed)
+	assert.True(t, errors.Is(err, types.ErrExpiredTx), "got %v", err)
+	assert.Equal(t, 1, pushes)
+}
+
+func TestTxOptions_Nonce(t *testing.T) {
+	node, api := newSubmitNode(t)
+
+	opts := &types.TxOptions{Nonce: true}
+	first, second := signTransfer(t, api, opts), signTransfer(t, api, opts)
+	firstID, err := first.TransactionID()
+	require.NoError(t, err)
+	secondID, err := second.TransactionID()
+	require.NoError(t, err)
+	assert.NotEqual(t, firstID, secondID)
+
+	for _, packed := range []*types.PackedTransaction{first, second} {
+		_, err := api.SubmitTransaction(packed)
+		require.NoError(t, err)
+	}
+	require.Len(t, node.Pushed(), 2)
+
+	signedTx, err := node.Pushed()[0].Unpack()
+	require.NoError(t, err)
+	require.Len(t, signedTx.Actions, 2)
+	assert.Equal(t, types.AccountName("eosio.null"), signedTx.Actions[1].Account)
+	assert.Equal(t, types.ActionName("nonce"), signedTx.Actions[1].Name)
+}
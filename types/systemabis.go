@@ -0,0 +1,246 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SystemABIs returns the ABIs of the system contracts, for decoding
+// their actions without a node: the native and common actions of
+// `eosio`, `eosio.token` and `eosio.null`.  They are trimmed down
+// versions of the ABIs of the eosio.contracts, so fetch the real ones
+// when you need their tables or less common actions.
+func SystemABIs() map[AccountName]*ABI {
+	abis := map[AccountName]*ABI{}
+	for account, def := range systemABIs {
+		var abi ABI
+		if err := json.Unmarshal([]byte(def), &abi); err != nil {
+			panic(fmt.Sprintf("system ABI of %s: %s", account, err))
+		}
+		abis[account] = &abi
+	}
+	return abis
+}
+
+var systemABIs = map[AccountName]string{
+	AN("eosio"):       eosioABI,
+	AN("eosio.token"): eosioTokenABI,
+	AN("eosio.null"):  eosioNullABI,
+}
+
+const eosioABI = `{
+	"version": "eosio::abi/1.1",
+	"types": [],
+	"structs": [
+		{"name": "permission_level", "base": "", "fields": [
+			{"name": "actor", "type": "name"},
+			{"name": "permission", "type": "name"}
+		]},
+		{"name": "key_weight", "base": "", "fields": [
+			{"name": "key", "type": "public_key"},
+			{"name": "weight", "type": "uint16"}
+		]},
+		{"name": "permission_level_weight", "base": "", "fields": [
+			{"name": "permission", "type": "permission_level"},
+			{"name": "weight", "type": "uint16"}
+		]},
+		{"name": "wait_weight", "base": "", "fields": [
+			{"name": "wait_sec", "type": "uint32"},
+			{"name": "weight", "type": "uint16"}
+		]},
+		{"name": "authority", "base": "", "fields": [
+			{"name": "threshold", "type": "uint32"},
+			{"name": "keys", "type": "key_weight[]"},
+			{"name": "accounts", "type": "permission_level_weight[]"},
+			{"name": "waits", "type": "wait_weight[]"}
+		]},
+		{"name": "newaccount", "base": "", "fields": [
+			{"name": "creator", "type": "name"},
+			{"name": "name", "type": "name"},
+			{"name": "owner", "type": "authority"},
+			{"name": "active", "type": "authority"}
+		]},
+		{"name": "setcode", "base": "", "fields": [
+			{"name": "account", "type": "name"},
+			{"name": "vmtype", "type": "uint8"},
+			{"name": "vmversion", "type": "uint8"},
+			{"name": "code", "type": "bytes"}
+		]},
+		{"name": "setabi", "base": "", "fields": [
+			{"name": "account", "type": "name"},
+			{"name": "abi", "type": "bytes"}
+		]},
+		{"name": "updateauth", "base": "", "fields": [
+			{"name": "account", "type": "name"},
+			{"name": "permission", "type": "name"},
+			{"name": "parent", "type": "name"},
+			{"name": "auth", "type": "authority"}
+		]},
+		{"name": "deleteauth", "base": "", "fields": [
+			{"name": "account", "type": "name"},
+			{"name": "permission", "type": "name"}
+		]},
+		{"name": "linkauth", "base": "", "fields": [
+			{"name": "account", "type": "name"},
+			{"name": "code", "type": "name"},
+			{"name": "type", "type": "name"},
+			{"name": "requirement", "type": "name"}
+		]},
+		{"name": "unlinkauth", "base": "", "fields": [
+			{"name": "account", "type": "name"},
+			{"name": "code", "type": "name"},
+			{"name": "type", "type": "name"}
+		]},
+		{"name": "canceldelay", "base": "", "fields": [
+			{"name": "canceling_auth", "type": "permission_level"},
+			{"name": "trx_id", "type": "checksum256"}
+		]},
+		{"name": "onerror", "base": "", "fields": [
+			{"name": "sender_id", "type": "uint128"},
+			{"name": "sent_trx", "type": "bytes"}
+		]},
+		{"name": "buyram", "base": "", "fields": [
+			{"name": "payer", "type": "name"},
+			{"name": "receiver", "type": "name"},
+			{"name": "quant", "type": "asset"}
+		]},
+		{"name": "buyrambytes", "base": "", "fields": [
+			{"name": "payer", "type": "name"},
+			{"name": "receiver", "type": "name"},
+			{"name": "bytes", "type": "uint32"}
+		]},
+		{"name": "sellram", "base": "", "fields": [
+			{"name": "account", "type": "name"},
+			{"name": "bytes", "type": "int64"}
+		]},
+		{"name": "delegatebw", "base": "", "fields": [
+			{"name": "from", "type": "name"},
+			{"name": "receiver", "type": "name"},
+			{"name": "stake_net_quantity", "type": "asset"},
+			{"name": "stake_cpu_quantity", "type": "asset"},
+			{"name": "transfer", "type": "bool"}
+		]},
+		{"name": "undelegatebw", "base": "", "fields": [
+			{"name": "from", "type": "name"},
+			{"name": "receiver", "type": "name"},
+			{"name": "unstake_net_quantity", "type": "asset"},
+			{"name": "unstake_cpu_quantity", "type": "asset"}
+		]},
+		{"name": "refund", "base": "", "fields": [
+			{"name": "owner", "type": "name"}
+		]},
+		{"name": "regproducer", "base": "", "fields": [
+			{"name": "producer", "type": "name"},
+			{"name": "producer_key", "type": "public_key"},
+			{"name": "url", "type": "string"},
+			{"name": "location", "type": "uint16"}
+		]},
+		{"name": "unregprod", "base": "", "fields": [
+			{"name": "producer", "type": "name"}
+		]},
+		{"name": "voteproducer", "base": "", "fields": [
+			{"name": "voter", "type": "name"},
+			{"name": "proxy", "type": "name"},
+			{"name": "producers", "type": "name[]"}
+		]},
+		{"name": "regproxy", "base": "", "fields": [
+			{"name": "proxy", "type": "name"},
+			{"name": "isproxy", "type": "bool"}
+		]},
+		{"name": "claimrewards", "base": "", "fields": [
+			{"name": "owner", "type": "name"}
+		]}
+	],
+	"actions": [
+		{"name": "newaccount", "type": "newaccount", "ricardian_contract": ""},
+		{"name": "setcode", "type": "setcode", "ricardian_contract": ""},
+		{"name": "setabi", "type": "setabi", "ricardian_contract": ""},
+		{"name": "updateauth", "type": "updateauth", "ricardian_contract": ""},
+		{"name": "deleteauth", "type": "deleteauth", "ricardian_contract": ""},
+		{"name": "linkauth", "type": "linkauth", "ricardian_contract": ""},
+		{"name": "unlinkauth", "type": "unlinkauth", "ricardian_contract": ""},
+		{"name": "canceldelay", "type": "canceldelay", "ricardian_contract": ""},
+		{"name": "onerror", "type": "onerror", "ricardian_contract": ""},
+		{"name": "buyram", "type": "buyram", "ricardian_contract": ""},
+		{"name": "buyrambytes", "type": "buyrambytes", "ricardian_contract": ""},
+		{"name": "sellram", "type": "sellram", "ricardian_contract": ""},
+		{"name": "delegatebw", "type": "delegatebw", "ricardian_contract": ""},
+		{"name": "undelegatebw", "type": "undelegatebw", "ricardian_contract": ""},
+		{"name": "refund", "type": "refund", "ricardian_contract": ""},
+		{"name": "regproducer", "type": "regproducer", "ricardian_contract": ""},
+		{"name": "unregprod", "type": "unregprod", "ricardian_contract": ""},
+		{"name": "voteproducer", "type": "voteproducer", "ricardian_contract": ""},
+		{"name": "regproxy", "type": "regproxy", "ricardian_contract": ""},
+		{"name": "claimrewards", "type": "claimrewards", "ricardian_contract": ""}
+	],
+	"tables": []
+}`
+
+const eosioTokenABI = `{
+	"version": "eosio::abi/1.1",
+	"types": [],
+	"structs": [
+		{"name": "create", "base": "", "fields": [
+			{"name": "issuer", "type": "name"},
+			{"name": "maximum_supply", "type": "asset"}
+		]},
+		{"name": "issue", "base": "", "fields": [
+			{"name": "to", "type": "name"},
+			{"name": "quantity", "type": "asset"},
+			{"name": "memo", "type": "string"}
+		]},
+		{"name": "retire", "base": "", "fields": [
+			{"name": "quantity", "type": "asset"},
+			{"name": "memo", "type": "string"}
+		]},
+		{"name": "transfer", "base": "", "fields": [
+			{"name": "from", "type": "name"},
+			{"name": "to", "type": "name"},
+			{"name": "quantity", "type": "asset"},
+			{"name": "memo", "type": "string"}
+		]},
+		{"name": "open", "base": "", "fields": [
+			{"name": "owner", "type": "name"},
+			{"name": "symbol", "type": "symbol"},
+			{"name": "ram_payer", "type": "name"}
+		]},
+		{"name": "close", "base": "", "fields": [
+			{"name": "owner", "type": "name"},
+			{"name": "symbol", "type": "symbol"}
+		]},
+		{"name": "account", "base": "", "fields": [
+			{"name": "balance", "type": "asset"}
+		]},
+		{"name": "currency_stats", "base": "", "fields": [
+			{"name": "supply", "type": "asset"},
+			{"name": "max_supply", "type": "asset"},
+			{"name": "issuer", "type": "name"}
+		]}
+	],
+	"actions": [
+		{"name": "create", "type": "create", "ricardian_contract": ""},
+		{"name": "issue", "type": "issue", "ricardian_contract": ""},
+		{"name": "retire", "type": "retire", "ricardian_contract": ""},
+		{"name": "transfer", "type": "transfer", "ricardian_contract": ""},
+		{"name": "open", "type": "open", "ricardian_contract": ""},
+		{"name": "close", "type": "close", "ricardian_contract": ""}
+	],
+	"tables": [
+		{"name": "accounts", "index_type": "i64", "key_names": [], "key_types": [], "type": "account"},
+		{"name": "stat", "index_type": "i64", "key_names": [], "key_types": [], "type": "currency_stats"}
+	]
+}`
+
+const eosioNullABI = `{
+	"version": "eosio::abi/1.1",
+	"types": [],
+	"structs": [
+		{"name": "nonce", "base": "", "fields": [
+			{"name": "value", "type": "string"}
+		]}
+	],
+	"actions": [
+		{"name": "nonce", "type": "nonce", "ricardian_contract": ""}
+	],
+	"tables": []
+}`
@@ -0,0 +1,372 @@
+package types
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// TableRowsIterator walks all the pages of a `get_table_rows` query:
+//
+//	it := api.IterateTableRows(ctx, GetTableRowsRequest{Code: "eosio.token", Scope: "eosio", Table: "stat", JSON: true, Limit: 100})
+//	for it.Next() {
+//		var rows []Stat
+//		if err := it.Rows(&rows); err != nil {
+//			return err
+//		}
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+//
+// The next page starts at the `next_key` returned by the node.  Older
+// nodes don't return it: set PrimaryKey so the iterator can compute
+// the next bound from the last row of the page.  That fallback only
+// works on the primary index.
+type TableRowsIterator struct {
+	// PrimaryKey extracts the primary key of a row, used when the node
+	// doesn't return `next_key`. See PrimaryKeyField.
+	PrimaryKey func(row json.RawMessage) (uint64, error)
+
+	api    *API
+	ctx    context.Context
+	params GetTableRowsRequest
+
+	page *GetTableRowsResp
+	done bool
+	err  error
+}
+
+// IterateTableRows returns an iterator over all the rows matching
+// `params`, in pages of `params.Limit` rows.  The lower bound (or the
+// upper bound with `params.Reverse`) moves as pages are fetched.
+func (api *API) IterateTableRows(ctx context.Context, params GetTableRowsRequest) *TableRowsIterator {
+	return &TableRowsIterator{
+		api:    api,
+		ctx:    ctx,
+		params: params,
+	}
+}
+
+// Next fetches the next page. It returns false when there are no more
+// pages, or on error (check Err).
+func (it *TableRowsIterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+
+	if it.page != nil {
+		if !it.page.More {
+			it.done = true
+			return false
+		}
+
+		more, err := it.moveBounds()
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if !more {
+			it.done = true
+			return false
+		}
+	}
+
+	page, err := it.api.GetTableRowsContext(it.ctx, it.params)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.page = page
+
+	return true
+}
+
+// Page returns the raw response of the current page.
+func (it *TableRowsIterator) Page() *GetTableRowsResp {
+	return it.page
+}
+
+// Rows decodes the rows of the current page into `v`, a pointer to a
+// slice, through JSONToStructs or BinaryToStructs depending on the
+// `JSON` flag of the request. The slice is replaced, not appended to.
+func (it *TableRowsIterator) Rows(v interface{}) error {
+	if it.page == nil {
+		return errors.New("no current page, call Next first")
+	}
+
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("rows must be decoded into a pointer to a slice, got %T", v)
+	}
+	slice.Elem().Set(reflect.MakeSlice(slice.Elem().Type(), 0, 0))
+
+	if it.params.JSON {
+		return it.page.JSONToStructs(v)
+	}
+	return it.page.BinaryToStructs(v)
+}
+
+func (it *TableRowsIterator) Err() error {
+	return it.err
+}
+
+// moveBounds sets the bounds for the next page. It returns false when
+// the bounds can't go any further.
+func (it *TableRowsIterator) moveBounds() (bool, error) {
+	if it.page.NextKey != "" {
+		if it.params.Reverse {
+			it.params.UpperBound = it.page.NextKey
+		} else {
+			it.params.LowerBound = it.page.NextKey
+		}
+		return true, nil
+	}
+
+	if !it.primaryIndex() {
+		return false, errors.New("node didn't return next_key, secondary indexes can't be paginated without it")
+	}
+	if it.PrimaryKey == nil {
+		return false, errors.New("node didn't return next_key, set PrimaryKey on the iterator to paginate")
+	}
+
+	var rows []json.RawMessage
+	if err := json.Unmarshal(it.page.Rows, &rows); err != nil {
+		return false, fmt.Errorf("reading rows: %w", err)
+	}
+	if len(rows) == 0 {
+		return false, errors.New("node returned more=true without any rows")
+	}
+
+	key, err := it.PrimaryKey(rows[len(rows)-1])
+	if err != nil {
+		return false, fmt.Errorf("primary key of last row: %w", err)
+	}
+
+	if it.params.Reverse {
+		if key == 0 {
+			return false, nil
+		}
+		it.params.UpperBound = strconv.FormatUint(key-1, 10)
+	} else {
+		if key == math.MaxUint64 {
+			return false, nil
+		}
+		it.params.LowerBound = strconv.FormatUint(key+1, 10)
+	}
+	return true, nil
+}
+
+func (it *TableRowsIterator) primaryIndex() bool {
+	switch it.params.IndexPosition {
+	case "", "1", "primary":
+		return true
+	}
+	return false
+}
+
+// PrimaryKeyField returns a PrimaryKey function reading `field` from
+// JSON rows. The field can hold a number, a number in a string, or a
+// name (like `account`).
+func PrimaryKeyField(field string) func(row json.RawMessage) (uint64, error) {
+	return func(row json.RawMessage) (uint64, error) {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(row, &obj); err != nil {
+			return 0, err
+		}
+
+		raw, ok := obj[field]
+		if !ok {
+			return 0, fmt.Errorf("field %q not found in row", field)
+		}
+
+		var num uint64
+		if err := json.Unmarshal(raw, &num); err == nil {
+			return num, nil
+		}
+
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return 0, fmt.Errorf("field %q is neither a number nor a string", field)
+		}
+		if num, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return num, nil
+		}
+		return StringToName(s)
+	}
+}
+
+// TableKey is a bound of a `get_table_rows` query, with the
+// `key_type` and `encode_type` telling `nodeos` how to read it.
+// Build them with I64Key, NameKey, SHA256Key and friends.
+type TableKey struct {
+	KeyType    string
+	EncodeType string
+	Value      string
+}
+
+func I64Key(v uint64) TableKey {
+	return TableKey{KeyType: "i64", EncodeType: "dec", Value: strconv.FormatUint(v, 10)}
+}
+
+// I128Key encodes an unsigned 128 bits key.  It fails if `v` is
+// negative or doesn't fit in 128 bits.
+func I128Key(v *big.Int) (TableKey, error) {
+	value, err := unsignedKey(v, 128)
+	if err != nil {
+		return TableKey{}, err
+	}
+	return TableKey{KeyType: "i128", EncodeType: "dec", Value: value}, nil
+}
+
+func MustI128Key(v *big.Int) TableKey {
+	key, err := I128Key(v)
+	if err != nil {
+		panic(err.Error())
+	}
+	return key
+}
+
+// I256Key encodes an unsigned 256 bits key.  It fails if `v` is
+// negative or doesn't fit in 256 bits.  It's sent in decimal: with
+// `hex`, `nodeos` reads i256 bounds as checksums, not as integers.
+func I256Key(v *big.Int) (TableKey, error) {
+	value, err := unsignedKey(v, 256)
+	if err != nil {
+		return TableKey{}, err
+	}
+	return TableKey{KeyType: "i256", EncodeType: "dec", Value: value}, nil
+}
+
+func MustI256Key(v *big.Int) TableKey {
+	key, err := I256Key(v)
+	if err != nil {
+		panic(err.Error())
+	}
+	return key
+}
+
+func unsignedKey(v *big.Int, bits int) (string, error) {
+	if v.Sign() < 0 || v.BitLen() > bits {
+		return "", fmt.Errorf("table key %s doesn't fit in an unsigned %d bits integer", v, bits)
+	}
+	return v.String(), nil
+}
+
+func Float64Key(v float64) TableKey {
+	return TableKey{KeyType: "float64", EncodeType: "dec", Value: strconv.FormatFloat(v, 'g', -1, 64)}
+}
+
+// Float128Key encodes a `float128` key. `nodeos` reads the bound as a
+// double before widening it, so it can't carry more precision.
+func Float128Key(v float64) TableKey {
+	return TableKey{KeyType: "float128", EncodeType: "dec", Value: strconv.FormatFloat(v, 'g', -1, 64)}
+}
+
+func SHA256Key(v SHA256Bytes) TableKey {
+	return TableKey{KeyType: "sha256", EncodeType: "hex", Value: hex.EncodeToString(v)}
+}
+
+// RIPEMD160Key encodes a `checksum160` key, `v` should be 20 bytes.
+func RIPEMD160Key(v []byte) TableKey {
+	return TableKey{KeyType: "ripemd160", EncodeType: "hex", Value: hex.EncodeToString(v)}
+}
+
+func NameKey(v Name) TableKey {
+	return TableKey{KeyType: "name", Value: string(v)}
+}
+
+// SetIndex targets the index at `position`, 1 being the primary index
+// and 2 the first secondary index, between `lower` and `upper`
+// (inclusive).  Leave a bound to the zero TableKey to keep it open.
+// Both bounds must have the same key type.
+func (r *GetTableRowsRequest) SetIndex(position int, lower, upper TableKey) {
+	r.IndexPosition = strconv.Itoa(position)
+	r.LowerBound = lower.Value
+	r.UpperBound = upper.Value
+
+	key := lower
+	if key.KeyType == "" {
+		key = upper
+	}
+	r.KeyType = key.KeyType
+	r.EncodeType = key.EncodeType
+}
+
+// TableScopeIterator walks all the pages of a `get_table_by_scope`
+// query.  Use it with IterateTableRows to read a table over all its
+// scopes:
+//
+//	scopes := api.IterateTableByScope(ctx, GetTableByScopeRequest{Code: "eosio.token", Table: "accounts"})
+//	for scopes.Next() {
+//		for _, scope := range scopes.Scopes() {
+//			rows := api.IterateTableRows(ctx, GetTableRowsRequest{Code: "eosio.token", Scope: string(scope.Scope), Table: "accounts", JSON: true})
+//			...
+//		}
+//	}
+type TableScopeIterator struct {
+	api    *API
+	ctx    context.Context
+	params GetTableByScopeRequest
+
+	page *GetTableByScopeResp
+	err  error
+}
+
+// IterateTableByScope returns an iterator over all the scopes matching
+// `params`, in pages of `params.Limit` scopes.
+func (api *API) IterateTableByScope(ctx context.Context, params GetTableByScopeRequest) *TableScopeIterator {
+	return &TableScopeIterator{
+		api:    api,
+		ctx:    ctx,
+		params: params,
+	}
+}
+
+// Next fetches the next page. It returns false when there are no more
+// pages, or on error (check Err).
+func (it *TableScopeIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.page != nil {
+		if it.page.More == "" {
+			return false
+		}
+
+		if it.params.Reverse {
+			it.params.UpperBound = it.page.More
+		} else {
+			it.params.LowerBound = it.page.More
+		}
+	}
+
+	page, err := it.api.GetTableByScopeContext(it.ctx, it.params)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.page = page
+
+	return true
+}
+
+// Scopes returns the scopes of the current page.
+func (it *TableScopeIterator) Scopes() []TableScope {
+	if it.page == nil {
+		return nil
+	}
+	return it.page.Rows
+}
+
+func (it *TableScopeIterator) Err() error {
+	return it.err
+}
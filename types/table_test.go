@@ -0,0 +1,255 @@
+package types_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRow struct {
+	ID    uint64            `json:"id"`
+	Owner types.AccountName `json:"owner"`
+}
+
+// newTestTable serves `get_table_rows` over rows with ids 0 to
+// count-1, returning `next_key` only if `withNextKey` is set.
+func newTestTable(t *testing.T, count uint64, withNextKey bool, requests *[]types.GetTableRowsRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/chain/get_table_rows", r.URL.Path)
+
+		var req types.GetTableRowsRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		*requests = append(*requests, req)
+
+		lower, upper := uint64(0), count-1
+		if req.LowerBound != "" {
+			lower, _ = strconv.ParseUint(req.LowerBound, 10, 64)
+		}
+		if req.UpperBound != "" {
+			upper, _ = strconv.ParseUint(req.UpperBound, 10, 64)
+		}
+
+		var ids []uint64
+		for id := lower; id <= upper && id < count; id++ {
+			ids = append(ids, id)
+		}
+		if req.Reverse {
+			for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+				ids[i], ids[j] = ids[j], ids[i]
+			}
+		}
+
+		resp := map[string]interface{}{}
+		if uint32(len(ids)) > req.Limit {
+			resp["more"] = true
+			if withNextKey {
+				resp["next_key"] = fmt.Sprintf("%d", ids[req.Limit])
+			}
+			ids = ids[:req.Limit]
+		}
+
+		var rows []interface{}
+		for _, id := range ids {
+			row := testRow{ID: id, Owner: types.AccountName("eosio")}
+			if req.JSON {
+				rows = append(rows, row)
+				continue
+			}
+			bin, err := types.MarshalBinary(row)
+			require.NoError(t, err)
+			rows = append(rows, hex.EncodeToString(bin))
+		}
+		resp["rows"] = rows
+
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestTableRowsIterator(t *testing.T) {
+	tests := []struct {
+		name        string
+		withNextKey bool
+		json        bool
+		reverse     bool
+		expected    []uint64
+	}{
+		{"next_key", true, true, false, []uint64{0, 1, 2, 3, 4, 5, 6}},
+		{"primary key", false, true, false, []uint64{0, 1, 2, 3, 4, 5, 6}},
+		{"next_key reverse", true, true, true, []uint64{6, 5, 4, 3, 2, 1, 0}},
+		{"primary key reverse", false, true, true, []uint64{6, 5, 4, 3, 2, 1, 0}},
+		{"binary", true, false, false, []uint64{0, 1, 2, 3, 4, 5, 6}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests []types.GetTableRowsRequest
+			server := newTestTable(t, 7, test.withNextKey, &requests)
+			defer server.Close()
+
+			api := types.New(server.URL)
+			it := api.IterateTableRows(context.Background(), types.GetTableRowsRequest{
+				Code:    "eosio",
+				Scope:   "eosio",
+				Table:   "test",
+				JSON:    test.json,
+				Limit:   3,
+				Reverse: test.reverse,
+			})
+			it.PrimaryKey = types.PrimaryKeyField("id")
+
+			var ids []uint64
+			for it.Next() {
+				var rows []testRow
+				require.NoError(t, it.Rows(&rows))
+				assert.True(t, len(rows) <= 3)
+				for _, row := range rows {
+					ids = append(ids, row.ID)
+					assert.Equal(t, types.AccountName("eosio"), row.Owner)
+				}
+			}
+			require.NoError(t, it.Err())
+
+			assert.Equal(t, test.expected, ids)
+			assert.Len(t, requests, 3)
+		})
+	}
+}
+
+func TestTableRowsIterator_NoNextKey(t *testing.T) {
+	var requests []types.GetTableRowsRequest
+	server := newTestTable(t, 7, false, &requests)
+	defer server.Close()
+
+	api := types.New(server.URL)
+	it := api.IterateTableRows(context.Background(), types.GetTableRowsRequest{Code: "eosio", Scope: "eosio", Table: "test", JSON: true, Limit: 3})
+
+	assert.True(t, it.Next())
+	assert.False(t, it.Next())
+	assert.Error(t, it.Err())
+}
+
+func TestPrimaryKeyField(t *testing.T) {
+	key, err := types.PrimaryKeyField("id")(json.RawMessage(`{"id":42}`))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), key)
+
+	key, err = types.PrimaryKeyField("id")(json.RawMessage(`{"id":"18446744073709551615"}`))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(18446744073709551615), key)
+
+	key, err = types.PrimaryKeyField("account")(json.RawMessage(`{"account":"eosio"}`))
+	require.NoError(t, err)
+	expected, _ := types.StringToName("eosio")
+	assert.Equal(t, expected, key)
+
+	_, err = types.PrimaryKeyField("id")(json.RawMessage(`{"key":1}`))
+	assert.Error(t, err)
+}
+
+func TestTableKeys(t *testing.T) {
+	i128, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	i256, _ := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
+	hash, _ := hex.DecodeString("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+
+	tests := []struct {
+		key      types.TableKey
+		expected types.TableKey
+	}{
+		{types.I64Key(18446744073709551615), types.TableKey{"i64", "dec", "18446744073709551615"}},
+		{types.MustI128Key(i128), types.TableKey{"i128", "dec", "340282366920938463463374607431768211455"}},
+		{types.MustI256Key(i256), types.TableKey{"i256", "dec", "115792089237316195423570985008687907853269984665640564039457584007913129639935"}},
+		{types.MustI256Key(big.NewInt(0x1234)), types.TableKey{"i256", "dec", "4660"}},
+		{types.Float64Key(1.5), types.TableKey{"float64", "dec", "1.5"}},
+		{types.Float128Key(-0.25), types.TableKey{"float128", "dec", "-0.25"}},
+		{types.SHA256Key(hash), types.TableKey{"sha256", "hex", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}},
+		{types.RIPEMD160Key(hash[:20]), types.TableKey{"ripemd160", "hex", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4"}},
+		{types.NameKey("eosio.token"), types.TableKey{"name", "", "eosio.token"}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.key)
+	}
+
+	_, err := types.I256Key(big.NewInt(-1))
+	assert.Error(t, err)
+	_, err = types.I256Key(new(big.Int).Add(i256, big.NewInt(1)))
+	assert.Error(t, err)
+	_, err = types.I128Key(new(big.Int).Add(i128, big.NewInt(1)))
+	assert.Error(t, err)
+	assert.Panics(t, func() { types.MustI128Key(big.NewInt(-1)) })
+}
+
+func TestGetTableRowsRequest_SetIndex(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Write([]byte(`{"rows":[],"more":false}`))
+	}))
+	defer server.Close()
+
+	req := types.GetTableRowsRequest{Code: "eosio", Scope: "eosio", Table: "voters", JSON: true}
+	req.SetIndex(2, types.TableKey{}, types.NameKey("bob"))
+
+	_, err := types.New(server.URL).GetTableRows(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2", body["index_position"])
+	assert.Equal(t, "name", body["key_type"])
+	assert.Equal(t, "", body["lower_bound"])
+	assert.Equal(t, "bob", body["upper_bound"])
+	assert.NotContains(t, body, "encode_type")
+}
+
+func TestTableScopeIterator(t *testing.T) {
+	scopes := []string{"alice", "bob", "carol", "dave", "eve"}
+
+	var requests []types.GetTableByScopeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/chain/get_table_by_scope", r.URL.Path)
+
+		var req types.GetTableByScopeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		resp := types.GetTableByScopeResp{Rows: []types.TableScope{}}
+		for _, scope := range scopes {
+			if scope < req.LowerBound {
+				continue
+			}
+			if uint32(len(resp.Rows)) == req.Limit {
+				resp.More = scope
+				break
+			}
+			resp.Rows = append(resp.Rows, types.TableScope{Code: "eosio.token", Scope: types.ScopeName(scope), Table: "accounts", Payer: types.AccountName(scope), Count: 1})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	api := types.New(server.URL)
+	it := api.IterateTableByScope(context.Background(), types.GetTableByScopeRequest{Code: "eosio.token", Table: "accounts", Limit: 2})
+
+	var found []string
+	for it.Next() {
+		for _, scope := range it.Scopes() {
+			found = append(found, string(scope.Scope))
+		}
+	}
+	require.NoError(t, it.Err())
+
+	assert.Equal(t, scopes, found)
+	require.Len(t, requests, 3)
+	assert.Equal(t, "", requests[0].LowerBound)
+	assert.Equal(t, "carol", requests[1].LowerBound)
+	assert.Equal(t, "eve", requests[2].LowerBound)
+}
@@ -0,0 +1,263 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+)
+
+type TxStatus int
+
+const (
+	TxPending      TxStatus = iota // not seen in a block yet
+	TxExecuted                     // in a block, not irreversible yet
+	TxForkedOut                    // the block holding it was dropped in a fork, it's pending again
+	TxIrreversible                 // in an irreversible block, final
+	TxExpired                      // expired without making it in a block, final
+)
+
+func (s TxStatus) String() string {
+	switch s {
+	case TxPending:
+		return "pending"
+	case TxExecuted:
+		return "executed"
+	case TxForkedOut:
+		return "forked out"
+	case TxIrreversible:
+		return "irreversible"
+	case TxExpired:
+		return "expired"
+	}
+	return "unknown"
+}
+
+// Final tells whether the status can't change anymore.
+func (s TxStatus) Final() bool {
+	return s == TxIrreversible || s == TxExpired
+}
+
+// TxState is reported by a TxTracker on every change.
+type TxState struct {
+	Status   TxStatus
+	BlockNum uint32      // block holding the transaction, when executed or irreversible
+	BlockID  SHA256Bytes // ID of that block
+	Receipt  *TransactionReceiptHeader
+
+	// Err is set on the last state sent by Watch when tracking
+	// failed.
+	Err error
+}
+
+// TxTracker follows a transaction until it is irreversible or
+// expired, polling `get_info` and reading each new block with
+// `get_block` to find it.
+type TxTracker struct {
+	// PollInterval defaults to 500ms, the block interval.
+	PollInterval time.Duration
+
+	// StartBlock is the first block searched. It defaults to the
+	// block following the reference block of the transaction, or
+	// to the one following the last irreversible block.
+	StartBlock uint32
+
+	api         *API
+	id          SHA256Bytes
+	expiration  time.Time
+	refBlockNum *uint16
+}
+
+// TrackTransaction returns a tracker for a transaction to push, or
+// already pushed.
+func (api *API) TrackTransaction(tx *PackedTransaction) (*TxTracker, error) {
+	id, err := tx.TransactionID()
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx, err := tx.Unpack()
+	if err != nil {
+		return nil, err
+	}
+
+	refBlockNum := signedTx.RefBlockNum
+	return &TxTracker{
+		api:         api,
+		id:          id,
+		expiration:  signedTx.Expiration.Time,
+		refBlockNum: &refBlockNum,
+	}, nil
+}
+
+// TrackTransactionID returns a tracker for a transaction known by its
+// ID only, like after SignPushActions.
+func (api *API) TrackTransactionID(id SHA256Bytes, expiration time.Time) *TxTracker {
+	return &TxTracker{
+		api:        api,
+		id:         id,
+		expiration: expiration,
+	}
+}
+
+func (t *TxTracker) ID() SHA256Bytes {
+	return t.id
+}
+
+// Wait blocks until the transaction reaches `status` (TxExecuted or
+// TxIrreversible), and returns the state at that moment.  It fails
+// with ErrExpiredTx if the transaction expired.
+func (t *TxTracker) Wait(ctx context.Context, status TxStatus) (TxState, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var last TxState
+	for state := range t.Watch(ctx) {
+		last = state
+		if state.Err != nil {
+			return state, state.Err
+		}
+		if state.Status == TxExpired {
+			return state, ErrExpiredTx
+		}
+		if state.Status == status || state.Status == TxIrreversible {
+			return state, nil
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return last, err
+	}
+	return last, errors.New("tracking stopped")
+}
+
+// Watch sends every state change of the transaction, starting with
+// TxPending, on the returned channel.  The channel is closed after a
+// final state, a failure (with `Err` set), or when `ctx` is done.
+func (t *TxTracker) Watch(ctx context.Context) <-chan TxState {
+	out := make(chan TxState)
+	go func() {
+		defer close(out)
+		t.run(ctx, out)
+	}()
+	return out
+}
+
+func (t *TxTracker) run(ctx context.Context, out chan<- TxState) {
+	interval := t.PollInterval
+	if interval == 0 {
+		interval = 500 * time.Millisecond
+	}
+
+	send := func(state TxState) bool {
+		select {
+		case out <- state:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	state := TxState{Status: TxPending}
+	if !send(state) {
+		return
+	}
+
+	nextBlock := t.StartBlock
+	for {
+		newState, err := t.poll(ctx, state, &nextBlock)
+		if err != nil && !IsRetryableError(err) {
+			if ctx.Err() == nil {
+				state.Err = err
+				send(state)
+			}
+			return
+		}
+
+		if err == nil && (newState.Status != state.Status || !bytes.Equal(newState.BlockID, state.BlockID)) {
+			state = newState
+			if !send(state) || state.Status.Final() {
+				return
+			}
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return
+		}
+	}
+}
+
+// poll checks on the transaction once, searching blocks from
+// `nextBlock` while it's pending.
+func (t *TxTracker) poll(ctx context.Context, state TxState, nextBlock *uint32) (TxState, error) {
+	info, err := t.api.GetInfoContext(ctx)
+	if err != nil {
+		return state, err
+	}
+
+	if *nextBlock == 0 {
+		*nextBlock = t.startBlock(info)
+	}
+
+	if state.Status == TxExecuted {
+		block, err := t.api.GetBlockByNumContext(ctx, state.BlockNum)
+		if err != nil {
+			return state, err
+		}
+
+		if !bytes.Equal(block.ID, state.BlockID) || t.findReceipt(block) == nil {
+			*nextBlock = state.BlockNum
+			return TxState{Status: TxForkedOut}, nil
+		}
+
+		if info.LastIrreversibleBlockNum >= state.BlockNum {
+			state.Status = TxIrreversible
+		}
+		return state, nil
+	}
+
+	for ; *nextBlock <= info.HeadBlockNum; *nextBlock++ {
+		block, err := t.api.GetBlockByNumContext(ctx, *nextBlock)
+		if err != nil {
+			return state, err
+		}
+
+		if receipt := t.findReceipt(block); receipt != nil {
+			status := TxExecuted
+			if info.LastIrreversibleBlockNum >= block.BlockNum {
+				status = TxIrreversible
+			}
+			*nextBlock++
+			return TxState{Status: status, BlockNum: block.BlockNum, BlockID: block.ID, Receipt: receipt}, nil
+		}
+	}
+
+	if !t.expiration.IsZero() && info.HeadBlockTime.After(t.expiration) {
+		return TxState{Status: TxExpired}, nil
+	}
+	return state, nil
+}
+
+func (t *TxTracker) startBlock(info *InfoResp) uint32 {
+	if t.refBlockNum == nil {
+		return info.LastIrreversibleBlockNum + 1
+	}
+
+	// Only the lower 16 bits of the reference block number are in
+	// the transaction, it's the most recent block matching them.
+	refBlock := info.HeadBlockNum&^0xffff | uint32(*t.refBlockNum)
+	if refBlock > info.HeadBlockNum && refBlock >= 0x10000 {
+		refBlock -= 0x10000
+	}
+	return refBlock + 1
+}
+
+func (t *TxTracker) findReceipt(block *BlockResp) *TransactionReceiptHeader {
+	for _, receipt := range block.Transactions {
+		if bytes.Equal(receipt.Transaction.ID, t.id) {
+			header := receipt.TransactionReceiptHeader
+			return &header
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package types_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Akagi201/eosgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBlockID(num uint32, fork byte) types.SHA256Bytes {
+	id := make([]byte, 32)
+	binary.BigEndian.PutUint32(id, num)
+	id[31] = fork
+	return id
+}
+
+func testPackedTransaction(t *testing.T, refBlock uint32, compression types.CompressionType) *types.PackedTransaction {
+	tx := types.NewTransaction(nil, &types.TxOptions{HeadBlockID: testBlockID(refBlock, 0)})
+	tx.Expiration = types.JSONTime{Time: time.Date(2018, 6, 1, 12, 0, 30, 0, time.UTC)}
+
+	packed, err := types.NewSignedTransaction(tx).Pack(compression)
+	require.NoError(t, err)
+	return packed
+}
+
+func TestTransaction_ID(t *testing.T) {
+	packed := testPackedTransaction(t, 2, types.CompressionNone)
+	compressed := testPackedTransaction(t, 2, types.CompressionZlib)
+
+	expected := sha256.Sum256(packed.PackedTransaction)
+
+	id, err := packed.TransactionID()
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(expected[:]), hex.EncodeToString(id))
+
+	id, err = compressed.TransactionID()
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(expected[:]), hex.EncodeToString(id))
+
+	signedTx, err := compressed.Unpack()
+	require.NoError(t, err)
+	id, err = signedTx.Transaction.TransactionID()
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(expected[:]), hex.EncodeToString(id))
+
+	// The original methods still give the same ID.
+	assert.Equal(t, hex.EncodeToString(expected[:]), hex.EncodeToString(compressed.ID()))
+	assert.Equal(t, hex.EncodeToString(expected[:]), signedTx.Transaction.ID())
+}
+
+// testChain is a fake chain advancing two blocks on each `get_info`.
+// The transaction is included in `txBlock`, in the block of fork
+// `txFork`; blocks of other forks are served once `forkAt` is reached.
+type testChain struct {
+	lock     sync.Mutex
+	head     uint32
+	packed   *types.PackedTransaction
+	txBlocks map[uint32]byte // block number => fork holding the transaction
+	forks    map[uint32]byte // block number => fork currently served
+	forkAt   uint32          // head from which `forks` is used
+}
+
+func (c *testChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	switch r.URL.Path {
+	case "/v1/chain/get_info":
+		c.head += 2
+		blockTime := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC).Add(time.Duration(c.head) * time.Second)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"head_block_num":              c.head,
+			"last_irreversible_block_num": c.head - 3,
+			"head_block_time":             blockTime.Format("2006-01-02T15:04:05"),
+		})
+	case "/v1/chain/get_block":
+		var params map[string]string
+		json.NewDecoder(r.Body).Decode(&params)
+		var num uint32
+		fmt.Sscanf(params["block_num_or_id"], "%d", &num)
+
+		fork := byte(0)
+		if c.head >= c.forkAt {
+			fork = c.forks[num]
+		}
+
+		receipts := []interface{}{}
+		if txFork, ok := c.txBlocks[num]; ok && txFork == fork {
+			receipts = append(receipts, map[string]interface{}{"status": "executed", "cpu_usage_us": 100, "net_usage_words": 12, "trx": c.packed})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":           testBlockID(num, fork),
+			"block_num":    num,
+			"timestamp":    "2018-06-01T12:00:00",
+			"transactions": receipts,
+		})
+	}
+}
+
+func TestTxTracker(t *testing.T) {
+	tests := []struct {
+		name     string
+		chain    *testChain
+		expected []types.TxState
+	}{
+		{
+			name:  "irreversible",
+			chain: &testChain{txBlocks: map[uint32]byte{5: 0}},
+			expected: []types.TxState{
+				{Status: types.TxPending},
+				{Status: types.TxExecuted, BlockNum: 5},
+				{Status: types.TxIrreversible, BlockNum: 5},
+			},
+		},
+		{
+			name:  "forked out",
+			chain: &testChain{txBlocks: map[uint32]byte{5: 0, 7: 1}, forks: map[uint32]byte{5: 1, 6: 1, 7: 1}, forkAt: 8},
+			expected: []types.TxState{
+				{Status: types.TxPending},
+				{Status: types.TxExecuted, BlockNum: 5},
+				{Status: types.TxForkedOut},
+				{Status: types.TxIrreversible, BlockNum: 7}, // already irreversible when found again
+			},
+		},
+		{
+			name:  "expired",
+			chain: &testChain{},
+			expected: []types.TxState{
+				{Status: types.TxPending},
+				{Status: types.TxExpired},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.chain.packed = testPackedTransaction(t, 2, types.CompressionZlib)
+			server := httptest.NewServer(test.chain)
+			defer server.Close()
+
+			tracker, err := types.New(server.URL).TrackTransaction(test.chain.packed)
+			require.NoError(t, err)
+			tracker.PollInterval = time.Millisecond
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			var states []types.TxState
+			for state := range tracker.Watch(ctx) {
+				require.NoError(t, state.Err)
+				state.BlockID = nil
+				state.Receipt = nil
+				states = append(states, state)
+			}
+			assert.Equal(t, test.expected, states)
+		})
+	}
+}
+
+func TestTxTracker_Wait(t *testing.T) {
+	chain := &testChain{txBlocks: map[uint32]byte{5: 0}}
+	chain.packed = testPackedTransaction(t, 2, types.CompressionNone)
+	server := httptest.NewServer(chain)
+	defer server.Close()
+
+	tracker, err := types.New(server.URL).TrackTransaction(chain.packed)
+	require.NoError(t, err)
+	tracker.PollInterval = time.Millisecond
+
+	state, err := tracker.Wait(context.Background(), types.TxExecuted)
+	require.NoError(t, err)
+	assert.Equal(t, types.TxExecuted, state.Status)
+	assert.Equal(t, uint32(5), state.BlockNum)
+	require.NotNil(t, state.Receipt)
+	assert.Equal(t, types.TransactionStatusExecuted, state.Receipt.Status)
+
+	expired := &testChain{}
+	expired.packed = testPackedTransaction(t, 2, types.CompressionNone)
+	server = httptest.NewServer(expired)
+	defer server.Close()
+
+	tracker, err = types.New(server.URL).TrackTransaction(expired.packed)
+	require.NoError(t, err)
+	tracker.PollInterval = time.Millisecond
+
+	_, err = tracker.Wait(context.Background(), types.TxIrreversible)
+	assert.True(t, errors.Is(err, types.ErrExpiredTx))
+}
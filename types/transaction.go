@@ -4,12 +4,13 @@ import (
 	"bytes"
 	"compress/flate"
 	"compress/zlib"
+	"context"
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"time"
 
@@ -40,8 +41,13 @@ func NewTransaction(actions []*Action, opts *TxOptions) *Transaction {
 		opts = &TxOptions{}
 	}
 
+	if opts.Nonce {
+		actions = append(append([]*Action{}, actions...), NewNonceAction())
+	}
+
 	tx := &Transaction{Actions: actions}
 	tx.Fill(opts.HeadBlockID, opts.DelaySecs, opts.MaxNetUsageWords, opts.MaxCPUUsageMS)
+	tx.Expiration = JSONTime{opts.expiration()}
 	return tx
 }
 
@@ -134,8 +140,26 @@ func (s *SignedTransaction) PackedTransactionAndCFD() ([]byte, []byte, error) {
 	return rawtrx, rawcfd, nil
 }
 
+// ID returns the transaction ID in hex, or an empty string if the
+// transaction doesn't encode.  See TransactionID.
 func (tx *Transaction) ID() string {
-	return "ID here" //todo
+	id, err := tx.TransactionID()
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// TransactionID returns the transaction ID, the sha256 of the packed
+// transaction (without signatures and context-free data).
+func (tx *Transaction) TransactionID() (SHA256Bytes, error) {
+	rawtrx, err := MarshalBinary(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.Sum256(rawtrx)
+	return h[:], nil
 }
 
 func (s *SignedTransaction) Pack(compression CompressionType) (*PackedTransaction, error) {
@@ -152,11 +176,13 @@ func (s *SignedTransaction) Pack(compression CompressionType) (*PackedTransactio
 		// Compress Trx
 		writer, _ := zlib.NewWriterLevel(&trx, flate.BestCompression) // can only fail if invalid `level`..
 		writer.Write(rawtrx)                                          // ignore error, could only bust memory
+		writer.Close()                                                // flushes, same as above
 		rawtrx = trx.Bytes()
 
 		// Compress ContextFreeData
 		writer, _ = zlib.NewWriterLevel(&cfd, flate.BestCompression) // can only fail if invalid `level`..
 		writer.Write(rawcfd)                                         // ignore errors, memory errors only
+		writer.Close()
 		rawcfd = cfd.Bytes()
 
 	}
@@ -181,33 +207,42 @@ type PackedTransaction struct {
 	PackedTransaction     HexBytes        `json:"packed_trx"`
 }
 
+// ID returns the transaction ID, or nil if the packed transaction
+// can't be uncompressed.  See TransactionID.
 func (p *PackedTransaction) ID() SHA256Bytes {
-	h := sha256.New()
-	_, _ = h.Write(p.PackedTransaction)
-	return h.Sum(nil)
+	id, _ := p.TransactionID()
+	return id
 }
 
-func (p *PackedTransaction) Unpack() (signedTx *SignedTransaction, err error) {
-	var txReader io.Reader
-	txReader = bytes.NewBuffer(p.PackedTransaction)
+// TransactionID returns the transaction ID, the sha256 of the
+// uncompressed packed transaction.
+func (p *PackedTransaction) TransactionID() (SHA256Bytes, error) {
+	rawtrx, err := p.rawTransaction()
+	if err != nil {
+		return nil, err
+	}
 
-	var freeDataReader io.Reader
-	freeDataReader = bytes.NewBuffer(p.PackedContextFreeData)
+	h := sha256.Sum256(rawtrx)
+	return h[:], nil
+}
 
-	switch p.Compression {
-	case CompressionZlib:
-		txReader, err = zlib.NewReader(txReader)
-		if err != nil {
-			return
-		}
+// rawTransaction returns the packed transaction, uncompressed.
+func (p *PackedTransaction) rawTransaction() ([]byte, error) {
+	if p.Compression != CompressionZlib {
+		return p.PackedTransaction, nil
+	}
 
-		freeDataReader, err = zlib.NewReader(freeDataReader)
-		if err != nil {
-			return
-		}
+	reader, err := zlib.NewReader(bytes.NewReader(p.PackedTransaction))
+	if err != nil {
+		return nil, fmt.Errorf("uncompressing transaction: %w", err)
 	}
+	defer reader.Close()
 
-	data, err := ioutil.ReadAll(txReader)
+	return ioutil.ReadAll(reader)
+}
+
+func (p *PackedTransaction) Unpack() (signedTx *SignedTransaction, err error) {
+	data, err := p.rawTransaction()
 	if err != nil {
 		return
 	}
@@ -248,34 +283,117 @@ type DeferredTransaction struct {
 // you're sending.
 type TxOptions struct {
 	ChainID          SHA256Bytes // If specified, we won't hit the API to fetch it
-	HeadBlockID      SHA256Bytes // If provided, don't hit API to fetch it.  This allows offline transaction signing.
+	HeadBlockID      SHA256Bytes // Block referenced by the transaction (see RefBlock). If provided, don't hit API to fetch it.  This allows offline transaction signing.
 	MaxNetUsageWords uint32
 	DelaySecs        uint32
 	MaxCPUUsageMS    uint8 // If you want to override the CPU usage (in counts of 1024)
 	//ExtraKCPUUsage uint32 // If you want to *add* some CPU usage to the estimated amount (in counts of 1024)
 	Compress CompressionType
+
+	// Expiration is how long the transaction stays valid, 30 seconds
+	// by default.  Nodes refuse expirations more than an hour away.
+	Expiration time.Duration
+
+	// RefBlock and RefBlocksBehind pick the block FillFromChain
+	// references in HeadBlockID (TaPoS).  The transaction is
+	// rejected by forks not having that block, so referencing an
+	// older block trades a bit of replay protection for robustness.
+	RefBlock        RefBlockPolicy
+	RefBlocksBehind uint32 // with RefBlockHead, reference the block this many blocks before the head
+
+	// UseNodeTime counts the expiration from the time of the node
+	// rather than from the local clock, which may be off.  The
+	// offset between both, NodeTimeOffset, is filled by
+	// FillFromChain when zero.
+	UseNodeTime    bool
+	NodeTimeOffset time.Duration
+
+	// Nonce appends an `eosio.null::nonce` action with a random value
+	// to the transaction, so that sending the same actions twice
+	// gives two distinct transactions.  See SubmitTransaction.
+	Nonce bool
+}
+
+// RefBlockPolicy is the block referenced by a transaction, see
+// TxOptions.RefBlock.
+type RefBlockPolicy int
+
+const (
+	RefBlockHead         RefBlockPolicy = iota // the head block, minus TxOptions.RefBlocksBehind
+	RefBlockIrreversible                       // the last irreversible block
+)
+
+func (opts *TxOptions) expiration() time.Time {
+	now := time.Now().UTC()
+	if opts.UseNodeTime {
+		now = now.Add(opts.NodeTimeOffset)
+	}
+
+	expiration := opts.Expiration
+	if expiration == 0 {
+		expiration = 30 * time.Second
+	}
+	return now.Add(expiration)
 }
 
-// FillFromChain will load ChainID (for signing transactions) and
-// HeadBlockID (to fill transaction with TaPoS data).
+// FillFromChain will load ChainID (for signing transactions),
+// HeadBlockID (to fill transaction with TaPoS data) and
+// NodeTimeOffset (with UseNodeTime).  The `get_info` answers are
+// cached, see API.InfoCache.
 func (opts *TxOptions) FillFromChain(api *API) error {
+	return opts.FillFromChainContext(context.Background(), api)
+}
+
+func (opts *TxOptions) FillFromChainContext(ctx context.Context, api *API) error {
 	if opts == nil {
 		return errors.New("TxOptions should not be nil, send an object")
 	}
 
-	if opts.HeadBlockID == nil || opts.ChainID == nil {
-		info, err := api.cachedGetInfo()
+	needTime := opts.UseNodeTime && opts.NodeTimeOffset == 0
+	if opts.HeadBlockID == nil || opts.ChainID == nil || needTime {
+		info, fetchedAt, err := api.cachedGetInfo(ctx)
 		if err != nil {
 			return err
 		}
 
 		if opts.HeadBlockID == nil {
-			opts.HeadBlockID = info.HeadBlockID
+			refBlockID, err := opts.refBlockID(ctx, api, info)
+			if err != nil {
+				return err
+			}
+			opts.HeadBlockID = refBlockID
 		}
 		if opts.ChainID == nil {
 			opts.ChainID = info.ChainID
 		}
+		if needTime {
+			// The head block time, as of when `info` was fetched.
+			opts.NodeTimeOffset = info.HeadBlockTime.Sub(fetchedAt)
+		}
 	}
 
 	return nil
 }
+
+func (opts *TxOptions) refBlockID(ctx context.Context, api *API, info *InfoResp) (SHA256Bytes, error) {
+	switch opts.RefBlock {
+	case RefBlockHead:
+		if opts.RefBlocksBehind == 0 {
+			return info.HeadBlockID, nil
+		}
+
+		num := uint32(1)
+		if info.HeadBlockNum > opts.RefBlocksBehind {
+			num = info.HeadBlockNum - opts.RefBlocksBehind
+		}
+		block, err := api.GetBlockByNumContext(ctx, num)
+		if err != nil {
+			return nil, fmt.Errorf("fetching reference block %d: %w", num, err)
+		}
+		return block.ID, nil
+	case RefBlockIrreversible:
+		return info.LastIrreversibleBlockID, nil
+	default:
+		return nil, fmt.Errorf("unknown reference block policy %d", opts.RefBlock)
+	}
+}
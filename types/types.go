@@ -2,6 +2,7 @@
 package types
 
 import (
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -91,7 +92,7 @@ func (c CompressionType) MarshalJSON() ([]byte, error) {
 
 func (c *CompressionType) UnmarshalJSON(data []byte) error {
 	switch string(data) {
-	case "zlib":
+	case `"zlib"`, "1":
 		*c = CompressionZlib
 	default:
 		*c = CompressionNone
@@ -330,6 +331,22 @@ type GetABIResp struct {
 	ABI         ABI         `json:"abi"`
 }
 
+type GetRawABIResp struct {
+	AccountName AccountName `json:"account_name"`
+	CodeHash    SHA256Bytes `json:"code_hash"`
+	ABIHash     SHA256Bytes `json:"abi_hash"`
+	ABI         Base64Bytes `json:"abi"`
+}
+
+// DecodeABI unpacks the `abi_def` of the response.
+func (r *GetRawABIResp) DecodeABI() (*ABI, error) {
+	var abi ABI
+	if err := abi.UnmarshalBinary(r.ABI); err != nil {
+		return nil, err
+	}
+	return &abi, nil
+}
+
 // JSONTime
 
 type JSONTime struct {
@@ -372,6 +389,24 @@ func (t *HexBytes) UnmarshalJSON(data []byte) (err error) {
 
 // SHA256Bytes
 
+// Base64Bytes are bytes given in base64 in JSON, with or without
+// padding, as nodeos sends them.
+type Base64Bytes []byte
+
+func (t Base64Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.StdEncoding.EncodeToString(t))
+}
+
+func (t *Base64Bytes) UnmarshalJSON(data []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(data, &s); err != nil {
+		return
+	}
+
+	*t, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+	return
+}
+
 type SHA256Bytes []byte // should always be 32 bytes
 
 func (t SHA256Bytes) MarshalJSON() ([]byte, error) {
@@ -432,8 +467,10 @@ type BlockTimestamp struct {
 
 const BlockTimestampFormat = "2006-01-02T15:04:05"
 
+// MarshalJSON keeps the milliseconds, block timestamps being on
+// half-seconds. They are accepted, though optional, when parsing.
 func (t BlockTimestamp) MarshalJSON() ([]byte, error) {
-	return []byte(fmt.Sprintf("%q", t.Format(BlockTimestampFormat))), nil
+	return []byte(fmt.Sprintf("%q", t.Format(BlockTimestampFormat+".000"))), nil
 }
 
 func (t *BlockTimestamp) UnmarshalJSON(data []byte) (err error) {